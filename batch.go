@@ -0,0 +1,174 @@
+package sego
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// 用workers个goroutine并行地对多段文本分词，返回的分词结果和texts一一对应
+//
+// workers不大于零时使用runtime.NumCPU()个goroutine。所有goroutine共享同一个
+// 词典，分词过程中不修改词典，因此是安全的。每段文本用SegmentContext分词，受
+// SetLimits设置的资源限制。ctx被取消或者某段文本分词出错时停止所有分词，返回nil
+// 和遇到的第一个错误。
+func (seg *Segmenter) SegmentBatch(ctx context.Context, texts [][]byte, workers int) ([][]Segment, error) {
+	results := make([][]Segment, len(texts))
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(texts) {
+		workers = len(texts)
+	}
+
+	// 出错时通过cancel通知其它goroutine停止
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var once sync.Once
+	var firstErr error
+
+	// 各个goroutine依次领取下一段文本，next为最后一段被领取的文本的下标
+	next := int64(-1)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				index := int(atomic.AddInt64(&next, 1))
+				if index >= len(texts) {
+					return
+				}
+				segments, err := seg.SegmentContext(ctx, texts[index])
+				if err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					return
+				}
+				results[index] = segments
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	// 有goroutine领取到了末尾说明所有文本都已分词，即使ctx在此期间被取消
+	if atomic.LoadInt64(&next) < int64(len(texts)) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// SegmentPipeline输出的一段文本的分词结果
+type PipelineResult struct {
+	Segments []Segment
+
+	// 分词出错时为SegmentContext返回的错误，比如ErrTextTooLong，此时Segments为nil
+	Err error
+}
+
+// 用workers个goroutine并行地对input中的文本分词，按输入的顺序将分词结果写入返回
+// 的channel
+//
+// workers不大于零时使用runtime.NumCPU()个goroutine。每段文本用SegmentContext
+// 分词，受SetLimits设置的资源限制；和SegmentBatch不同，一段文本出错时不停止
+// 其它文本的分词，错误在该文本的PipelineResult.Err中返回。input被关闭且所有文本
+// 的分词结果都写出后，返回的channel被关闭。ctx被取消时停止分词（包括正在分词的
+// 长文本）并关闭返回的channel，调用者可以检查ctx.Err()判断分词是否完整。文本在
+// 其分词结果被读出之前不能修改。
+//
+//	texts := make(chan []byte)
+//	go func() {
+//		defer close(texts)
+//		for ... {
+//			texts <- text
+//		}
+//	}()
+//	for result := range segmenter.SegmentPipeline(ctx, texts, 0) {
+//		if result.Err != nil {
+//			...
+//		}
+//	}
+func (seg *Segmenter) SegmentPipeline(ctx context.Context, input <-chan []byte, workers int) <-chan PipelineResult {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	// 每段文本对应一个缓冲为1的channel存放其分词结果，这些channel按输入的顺序
+	// 放入pending，由输出goroutine依次等待
+	type job struct {
+		text   []byte
+		result chan PipelineResult
+	}
+	jobs := make(chan job, workers)
+	pending := make(chan chan PipelineResult, workers*2)
+	output := make(chan PipelineResult, workers)
+
+	// 读入文本并分发给工作goroutine
+	go func() {
+		defer close(jobs)
+		defer close(pending)
+		for {
+			var text []byte
+			var ok bool
+			select {
+			case text, ok = <-input:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+
+			result := make(chan PipelineResult, 1)
+			select {
+			case jobs <- job{text, result}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case pending <- result:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// 工作goroutine
+	for i := 0; i < workers; i++ {
+		go func() {
+			for j := range jobs {
+				if ctx.Err() == nil {
+					segments, err := seg.SegmentContext(ctx, j.text)
+					j.result <- PipelineResult{Segments: segments, Err: err}
+				}
+			}
+		}()
+	}
+
+	// 按输入的顺序输出分词结果
+	go func() {
+		defer close(output)
+		for result := range pending {
+			select {
+			case r := <-result:
+				select {
+				case output <- r:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return output
+}
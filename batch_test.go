@@ -0,0 +1,95 @@
+package sego
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"testing"
+)
+
+func readTestLines() [][]byte {
+	content, _ := ioutil.ReadFile("testdata/bailuyuan.txt")
+	return bytes.Split(content[:50000], []byte("\n"))
+}
+
+func TestSegmentBatch(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	lines := readTestLines()
+
+	for _, workers := range []int{0, 1, 3, 1000} {
+		results, err := seg.SegmentBatch(context.Background(), lines, workers)
+		expect(t, "<nil>", err)
+		expect(t, fmt.Sprint(len(lines)), len(results))
+		for i, line := range lines {
+			expect(t, SegmentsToString(seg.Segment(line), false), SegmentsToString(results[i], false))
+		}
+	}
+
+	results, err := seg.SegmentBatch(context.Background(), nil, 0)
+	expect(t, "<nil>", err)
+	expect(t, "0", len(results))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err = seg.SegmentBatch(ctx, lines, 2)
+	expect(t, "context canceled", err)
+	expect(t, "0", len(results))
+}
+
+func TestSegmentPipeline(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	lines := readTestLines()
+
+	input := make(chan []byte)
+	go func() {
+		defer close(input)
+		for _, line := range lines {
+			input <- line
+		}
+	}()
+	i := 0
+	for result := range seg.SegmentPipeline(context.Background(), input, 4) {
+		expect(t, "<nil>", result.Err)
+		expect(t, SegmentsToString(seg.Segment(lines[i]), false), SegmentsToString(result.Segments, false))
+		i++
+	}
+	expect(t, fmt.Sprint(len(lines)), i)
+
+	// 超过资源限制的文本返回错误，不影响其它文本
+	seg.SetLimits(Limits{MaxTextBytes: 10})
+	input = make(chan []byte)
+	go func() {
+		defer close(input)
+		input <- []byte("中国")
+		input <- []byte("中国有十三亿人口")
+		input <- []byte("人口")
+	}()
+	results := []string{}
+	for result := range seg.SegmentPipeline(context.Background(), input, 2) {
+		results = append(results, fmt.Sprint(SegmentsToString(result.Segments, false), result.Err))
+	}
+	expect(t, "[中国/ <nil> sego: text too long 人口/p12 <nil>]", results)
+	seg.SetLimits(Limits{})
+
+	// 取消后返回的channel被关闭，即使input一直没有关闭
+	ctx, cancel := context.WithCancel(context.Background())
+	input = make(chan []byte)
+	go func() {
+		for _, line := range lines {
+			select {
+			case input <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	output := seg.SegmentPipeline(ctx, input, 2)
+	<-output
+	cancel()
+	for range output {
+	}
+	expect(t, "context canceled", ctx.Err())
+}
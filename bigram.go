@@ -0,0 +1,128 @@
+package sego
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// 二元组模型和一元模型插值时二元组条件概率的权重
+	bigramLambda = 0.8
+)
+
+// 词的二元组模型
+//
+// 对词典中相邻的两个分词w1、w2，其路径长度为
+//	-log2(λ*c(w1,w2)/c(w1,*) + (1-λ)*p(w2))
+// 其中c(w1,w2)为二元组的出现次数，c(w1,*)为w1后接任意分词的出现次数，p(w2)为
+// 一元模型中w2的概率。w1有后接分词但没有出现过的二元组c(w1,w2)为零，路径长度为
+//	-log2((1-λ)*p(w2))
+// 即Token.Distance()加上log2(1/(1-λ))，因此出现过的二元组总是比没有出现过的短。
+// w1没有后接任何分词时没有二元组信息，路径长度为一元模型的Token.Distance()。
+//
+// bigramModel实现了Scorer接口。
+type bigramModel struct {
+	distances map[[2]*Token]float32
+
+	// 有后接分词的分词，即c(w1,*)大于零的w1
+	prevs map[*Token]bool
+}
+
+// 没有出现过的二元组相对一元模型增加的路径长度，即log2(1/(1-λ))
+var bigramBackoff = float32(-math.Log2(1 - bigramLambda))
+
+// 返回分词token跟在分词prev之后时的路径长度
+func (model *bigramModel) Score(text []Text, start int, prev, token *Token) float32 {
+	if distance, ok := model.distances[[2]*Token{prev, token}]; ok {
+		return distance
+	}
+	if model.prevs[prev] {
+		return token.distance + bigramBackoff
+	}
+	return token.distance
+}
+
+// 清除LoadBigram载入的二元组模型，恢复默认的一元模型。SetScorer设置的其它Scorer
+// 不受影响。
+func (seg *Segmenter) clearBigram() {
+	if _, ok := seg.scorer.(*bigramModel); ok {
+		seg.scorer = nil
+	}
+}
+
+// 从文件中载入二元组模型，必须在LoadDictionary之后调用
+//
+// 可以载入多个文件，文件名用","分隔，同一个二元组出现多次时次数累加。
+//
+// 文件的格式为（每个二元组一行）：
+//	分词1 分词2 出现次数
+// 两个分词都必须在词典中，否则该行被忽略。
+//
+// 载入二元组模型后分词时会考虑相邻分词的搭配，比如根据"研究 生命"的出现次数
+// 将"研究生命起源"切分为"研究/生命/起源"而不是"研究生/命/起源"。二元组模型通过
+// SetScorer设置为分词器的Scorer，会替换之前设置的Scorer。二元组模型引用词典中的
+// 分词，重新载入词典时被清除，需要再次调用LoadBigram。
+func (seg *Segmenter) LoadBigram(files string) {
+	counts := make(map[[2]*Token]int)
+	totals := make(map[*Token]int)
+	for _, file := range strings.Split(files, ",") {
+		log.Printf("载入sego二元组模型 %s", file)
+		bigramFile, err := os.Open(file)
+		if err != nil {
+			log.Fatalf("无法载入二元组文件 \"%s\" \n", file)
+		}
+
+		reader := bufio.NewReader(bigramFile)
+		var text1, text2, countText string
+
+		// 逐行读入二元组
+		for {
+			size, _ := fmt.Fscanln(reader, &text1, &text2, &countText)
+			if size == 0 {
+				// 文件结束
+				break
+			} else if size < 3 {
+				// 无效行
+				continue
+			}
+
+			count, err := strconv.Atoi(countText)
+			if err != nil || count <= 0 {
+				continue
+			}
+
+			// 忽略不在词典中的分词
+			token1 := seg.dict.Lookup([]byte(text1))
+			token2 := seg.dict.Lookup([]byte(text2))
+			if token1 == nil || token2 == nil {
+				continue
+			}
+
+			counts[[2]*Token{token1, token2}] += count
+			totals[token1] += count
+		}
+		bigramFile.Close()
+	}
+
+	// 计算每个二元组的路径长度
+	model := &bigramModel{
+		distances: make(map[[2]*Token]float32, len(counts)),
+		prevs:     make(map[*Token]bool, len(totals)),
+	}
+	for token := range totals {
+		model.prevs[token] = true
+	}
+	for pair, count := range counts {
+		p := bigramLambda*float64(count)/float64(totals[pair[0]]) +
+			(1-bigramLambda)*math.Exp2(-float64(pair[1].distance))
+		model.distances[pair] = float32(-math.Log2(p))
+	}
+	seg.SetScorer(model)
+
+	log.Println("sego二元组模型载入完毕")
+}
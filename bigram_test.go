@@ -0,0 +1,57 @@
+package sego
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBigram(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict3.txt")
+	text := []byte("研究生命起源")
+	expect(t, "研究生/n 命/n 起源/n ", SegmentsToString(seg.Segment(text), false))
+
+	seg.LoadBigram("testdata/test_bigram.txt")
+	model := seg.Scorer().(*bigramModel)
+	expect(t, "2", len(model.distances))
+	expect(t, "研究/vn 生命/n 起源/n ", SegmentsToString(seg.Segment(text), false))
+
+	// 没有出现过的二元组回退到一元模型
+	yjs := seg.dict.Lookup([]byte("研究生"))
+	ming := seg.dict.Lookup([]byte("命"))
+	expect(t, "true", model.Score(nil, 0, yjs, ming) == ming.distance)
+
+	// 重新载入词典时清除引用旧词典的二元组模型
+	seg.LoadDictionary("testdata/test_dict3.txt")
+	expect(t, "sego.UnigramScorer", fmt.Sprintf("%T", seg.Scorer()))
+}
+
+func TestBigramBackoff(t *testing.T) {
+	// "研究"之后大多是"命"，"研究 生命"的条件概率很小
+	file := filepath.Join(t.TempDir(), "bigram.txt")
+	expect(t, "<nil>", os.WriteFile(file, []byte("研究 生命 10\n研究 命 1000\n"), 0644))
+
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict3.txt")
+	seg.LoadBigram(file)
+	model := seg.Scorer().(*bigramModel)
+	yanjiu := seg.dict.Lookup([]byte("研究"))
+	shengming := seg.dict.Lookup([]byte("生命"))
+	qiyuan := seg.dict.Lookup([]byte("起源"))
+	ming := seg.dict.Lookup([]byte("命"))
+
+	// "生命"和"起源"的词频相同，出现过的"研究 生命"比没有出现过的"研究 起源"路径短
+	expect(t, "true", shengming.distance == qiyuan.distance)
+	expect(t, "true", model.Score(nil, 0, yanjiu, shengming) < model.Score(nil, 0, yanjiu, qiyuan))
+
+	// 没有出现过的二元组的路径长度为-log2((1-λ)*p(w2))
+	unseen := float64(model.Score(nil, 0, yanjiu, qiyuan))
+	expect(t, "true", math.Abs(unseen-float64(qiyuan.distance)-math.Log2(1/(1-bigramLambda))) < 1e-5)
+
+	// 没有后接分词的分词和文本开头使用一元模型
+	expect(t, "true", model.Score(nil, 0, shengming, ming) == ming.distance)
+	expect(t, "true", model.Score(nil, 0, nil, ming) == ming.distance)
+}
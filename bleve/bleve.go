@@ -0,0 +1,190 @@
+// Package bleve 为全文搜索引擎bleve提供基于sego的分词器（tokenizer）和分析器
+// （analyzer）。
+//
+// 导入该包后即可在bleve的索引映射中按名字使用sego分词：
+//
+//	import segobleve "github.com/huichen/sego/bleve"
+//
+//	segobleve.SetSegmenter(&segmenter)
+//	indexMapping := bleve.NewIndexMapping()
+//	indexMapping.DefaultAnalyzer = segobleve.AnalyzerName
+//
+// 也可以在自定义分词器的配置中用"dict"指定词典文件，多个文件以逗号分隔，同一
+// 词典只载入一次：
+//
+//	indexMapping.AddCustomTokenizer("my_sego", map[string]interface{}{
+//		"type": segobleve.TokenizerName,
+//		"dict": "dictionary.txt",
+//		"search": true,
+//	})
+package bleve
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/blevesearch/bleve/v2/analysis"
+	"github.com/blevesearch/bleve/v2/registry"
+	"github.com/huichen/sego"
+)
+
+const (
+	// 普通模式的分词器和分析器
+	TokenizerName = "sego"
+	AnalyzerName  = "sego"
+
+	// 搜索模式的分词器和分析器，输出分词的子分词以提高召回率
+	SearchTokenizerName = "sego_search"
+	SearchAnalyzerName  = "sego_search"
+)
+
+var (
+	// 配置中没有指定词典时使用的分词器
+	defaultSegmenter *sego.Segmenter
+
+	// 按词典文件载入的分词器
+	segmenters = map[string]*sego.Segmenter{}
+
+	lock sync.Mutex
+)
+
+// 设置配置中没有指定词典时使用的分词器，应在创建索引之前调用
+func SetSegmenter(seg *sego.Segmenter) {
+	lock.Lock()
+	defer lock.Unlock()
+	defaultSegmenter = seg
+}
+
+// bleve分词器
+type Tokenizer struct {
+	seg *sego.Segmenter
+
+	// 是否为搜索模式
+	searchMode bool
+}
+
+// 创建使用seg分词的bleve分词器
+//
+// 搜索模式下分词的子分词和该分词位于同一个位置（Position），因此短语查询仍然
+// 按普通模式的分词匹配。
+func NewTokenizer(seg *sego.Segmenter, searchMode bool) *Tokenizer {
+	return &Tokenizer{seg: seg, searchMode: searchMode}
+}
+
+// 对文本分词，只由标点和空白组成的分词被忽略（见sego.IndexTerms）。分词的Term为分词文本，其中的英文
+// 为小写；Start和End为分词在文本中的字节位置；Type由分词的词性决定，见tokenType。
+func (t *Tokenizer) Tokenize(input []byte) analysis.TokenStream {
+	terms := sego.IndexTerms(t.seg.Segment(input), t.searchMode)
+	stream := make(analysis.TokenStream, 0, len(terms))
+	for i := range terms {
+		// bleve的位置从1开始
+		stream = append(stream, newToken(&terms[i].Segment, terms[i].Position+1))
+	}
+	return stream
+}
+
+func newToken(segment *sego.Segment, position int) *analysis.Token {
+	return &analysis.Token{
+		Start:    segment.Start(),
+		End:      segment.End(),
+		Term:     []byte(segment.Token().Text()),
+		Position: position,
+		Type:     tokenType(segment.Token()),
+	}
+}
+
+// 按词性决定分词的类型：数词（m）为Numeric，时间词（t）为DateTime，其余包含
+// 汉字的分词为Ideographic，否则为AlphaNumeric
+func tokenType(token *sego.Token) analysis.TokenType {
+	switch token.Pos() {
+	case "m":
+		return analysis.Numeric
+	case "t":
+		return analysis.DateTime
+	}
+	for _, r := range token.Text() {
+		if unicode.Is(unicode.Han, r) {
+			return analysis.Ideographic
+		}
+	}
+	for _, r := range token.Text() {
+		if !unicode.IsNumber(r) {
+			return analysis.AlphaNumeric
+		}
+	}
+	return analysis.Numeric
+}
+
+// 按配置返回分词器：配置中有"dict"时载入（或从缓存中取出）对应的词典，否则
+// 使用SetSegmenter设置的分词器
+func segmenterFromConfig(config map[string]interface{}) (*sego.Segmenter, error) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	dict, ok := config["dict"].(string)
+	if !ok || dict == "" {
+		if defaultSegmenter == nil {
+			return nil, fmt.Errorf("sego: no dictionary configured, call SetSegmenter or set \"dict\"")
+		}
+		return defaultSegmenter, nil
+	}
+	if seg, ok := segmenters[dict]; ok {
+		return seg, nil
+	}
+
+	// LoadDictionary在文件不存在时会退出程序，因此先检查
+	for _, file := range strings.Split(dict, ",") {
+		if _, err := os.Stat(file); err != nil {
+			return nil, err
+		}
+	}
+	seg := new(sego.Segmenter)
+	seg.LoadDictionary(dict)
+	segmenters[dict] = seg
+	return seg, nil
+}
+
+func newTokenizer(config map[string]interface{}, searchMode bool) (*Tokenizer, error) {
+	seg, err := segmenterFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	if search, ok := config["search"].(bool); ok {
+		searchMode = search
+	}
+	return NewTokenizer(seg, searchMode), nil
+}
+
+func tokenizerConstructor(config map[string]interface{}, cache *registry.Cache) (analysis.Tokenizer, error) {
+	return newTokenizer(config, false)
+}
+
+func searchTokenizerConstructor(config map[string]interface{}, cache *registry.Cache) (analysis.Tokenizer, error) {
+	return newTokenizer(config, true)
+}
+
+func analyzerConstructor(config map[string]interface{}, cache *registry.Cache) (analysis.Analyzer, error) {
+	tokenizer, err := newTokenizer(config, false)
+	if err != nil {
+		return nil, err
+	}
+	return &analysis.DefaultAnalyzer{Tokenizer: tokenizer}, nil
+}
+
+func searchAnalyzerConstructor(config map[string]interface{}, cache *registry.Cache) (analysis.Analyzer, error) {
+	tokenizer, err := newTokenizer(config, true)
+	if err != nil {
+		return nil, err
+	}
+	return &analysis.DefaultAnalyzer{Tokenizer: tokenizer}, nil
+}
+
+func init() {
+	registry.RegisterTokenizer(TokenizerName, tokenizerConstructor)
+	registry.RegisterTokenizer(SearchTokenizerName, searchTokenizerConstructor)
+	registry.RegisterAnalyzer(AnalyzerName, analyzerConstructor)
+	registry.RegisterAnalyzer(SearchAnalyzerName, searchAnalyzerConstructor)
+}
@@ -0,0 +1,80 @@
+package bleve
+
+import (
+	"fmt"
+	"testing"
+
+	blevesearch "github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis"
+	"github.com/huichen/sego"
+)
+
+func tokensToString(stream analysis.TokenStream) (output string) {
+	for _, token := range stream {
+		output += fmt.Sprintf("%s[%d,%d)@%d/%d ", token.Term, token.Start, token.End, token.Position, token.Type)
+	}
+	return
+}
+
+func TestTokenizer(t *testing.T) {
+	var seg sego.Segmenter
+	seg.LoadDictionary("../testdata/test_dict4.txt")
+	text := []byte("中华人民共和国, 1949 China")
+
+	expected := "中华人民共和国[0,21)@1/1 1949[23,27)@2/2 china[28,33)@3/0 "
+	if output := tokensToString(NewTokenizer(&seg, false).Tokenize(text)); output != expected {
+		t.Errorf("%s", output)
+	}
+	expected = "中华[0,6)@1/1 人民[6,12)@1/1 共和[12,18)@1/1 共和国[12,21)@1/1 人民共和国[6,21)@1/1 " +
+		"中华人民共和国[0,21)@1/1 1949[23,27)@2/2 china[28,33)@3/0 "
+	if output := tokensToString(NewTokenizer(&seg, true).Tokenize(text)); output != expected {
+		t.Errorf("%s", output)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	var seg sego.Segmenter
+	seg.LoadDictionary("../testdata/test_dict4.txt")
+	SetSegmenter(&seg)
+	defer SetSegmenter(nil)
+
+	indexMapping := blevesearch.NewIndexMapping()
+	indexMapping.DefaultAnalyzer = SearchAnalyzerName
+	index, err := blevesearch.NewMemOnly(indexMapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer index.Close()
+	index.Index("1", map[string]interface{}{"text": "中华人民共和国成立"})
+	index.Index("2", map[string]interface{}{"text": "共和"})
+
+	for query, expected := range map[string]uint64{"人民共和国": 1, "共和": 2, "中华": 1, "民国": 0} {
+		// 建立索引时用搜索模式，查询时用普通模式
+		match := blevesearch.NewMatchQuery(query)
+		match.Analyzer = AnalyzerName
+		request := blevesearch.NewSearchRequest(match)
+		result, err := index.Search(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Total != expected {
+			t.Errorf("%s: %d", query, result.Total)
+		}
+	}
+
+	// 通过配置指定词典
+	err = indexMapping.AddCustomTokenizer("my_sego", map[string]interface{}{
+		"type": TokenizerName,
+		"dict": "../testdata/test_dict4.txt",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = indexMapping.AddCustomTokenizer("missing", map[string]interface{}{
+		"type": TokenizerName,
+		"dict": "../testdata/missing.txt",
+	})
+	if err == nil {
+		t.Error("词典不存在时应返回错误")
+	}
+}
@@ -0,0 +1,181 @@
+package sego
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// 文本中的一段字节区间，包括Start不包括End
+type Span struct {
+	Start int
+	End   int
+}
+
+// 分词约束，其中的位置均为文本中的字节位置，且必须位于UTF8字符的边界上，
+// 否则该约束被忽略
+type Constraints struct {
+	// 必须作为一个完整分词的区间，比如商品目录中的产品名称。区间对应的文本不在
+	// 词典中时输出为词性为"x"的分词。和前面的区间重叠的区间被忽略。
+	Merges []Span
+
+	// 必须作为分词边界的位置，落在Merges区间内部的位置被忽略
+	Splits []int
+}
+
+// 按约束对文本分词
+//
+// 约束在动态规划中生效：跨越边界的候选分词和与合并区间部分重叠的候选分词不参与
+// 最短路径的计算，因此文本的其余部分仍然得到最优的分词划分。
+func (seg *Segmenter) SegmentWithConstraints(bytes []byte, constraints Constraints) []Segment {
+	// 处理特殊情况
+	if len(bytes) == 0 {
+		return []Segment{}
+	}
+
+	// 整理有效的合并区间和切分位置
+	isValid := func(position int) bool {
+		return position >= 0 && position <= len(bytes) &&
+			(position == len(bytes) || utf8.RuneStart(bytes[position]))
+	}
+	merges := []Span{}
+	for _, merge := range constraints.Merges {
+		if merge.Start >= merge.End || !isValid(merge.Start) || !isValid(merge.End) {
+			continue
+		}
+		overlapped := false
+		for _, m := range merges {
+			if merge.Start < m.End && m.Start < merge.End {
+				overlapped = true
+				break
+			}
+		}
+		if !overlapped {
+			merges = append(merges, merge)
+		}
+	}
+	sort.Slice(merges, func(i, j int) bool { return merges[i].Start < merges[j].Start })
+
+	cuts := []int{}
+	for _, merge := range merges {
+		cuts = append(cuts, merge.Start, merge.End)
+	}
+	for _, split := range constraints.Splits {
+		if !isValid(split) {
+			continue
+		}
+		inMerge := false
+		for _, m := range merges {
+			if split > m.Start && split < m.End {
+				inMerge = true
+				break
+			}
+		}
+		if !inMerge {
+			cuts = append(cuts, split)
+		}
+	}
+	sort.Ints(cuts)
+
+	// 划分字元，并在切分位置处切开字元
+	text, offsets := splitWordsAt(splitTextToWords(bytes), cuts)
+
+	// 将字节位置的约束转换为字元的约束
+	wc := &wordConstraints{
+		boundaries: make([]bool, len(text)),
+		merges:     make([]*wordMerge, len(text)),
+	}
+	iCut := 0
+	for i := range text {
+		for iCut < len(cuts) && cuts[iCut] < offsets[i] {
+			iCut++
+		}
+		wc.boundaries[i] = iCut < len(cuts) && cuts[iCut] == offsets[i]
+	}
+	iWord := 0
+	for _, merge := range merges {
+		for offsets[iWord] < merge.Start {
+			iWord++
+		}
+		m := &wordMerge{start: iWord}
+		for offsets[iWord+1] < merge.End {
+			iWord++
+		}
+		m.end = iWord
+		m.token = seg.dict.Lookup(bytes[merge.Start:merge.End])
+		if m.token == nil {
+			m.token = &Token{text: text[m.start : m.end+1], frequency: 1,
+				distance: UnknownTokenDistance, pos: "x"}
+		}
+		for i := m.start; i <= m.end; i++ {
+			wc.merges[i] = m
+		}
+	}
+
+	return seg.segmentWords(text, false, wc)
+}
+
+// 按字元表示的分词约束
+type wordConstraints struct {
+	// boundaries[i]为真表示第i个字元之前必须是分词边界
+	boundaries []bool
+
+	// merges[i]为第i个字元所在的合并区间，不在任何合并区间中时为nil
+	merges []*wordMerge
+}
+
+// 一个必须作为完整分词的字元区间
+type wordMerge struct {
+	// 区间的第一个和最后一个字元
+	start, end int
+
+	// 区间对应的分词
+	token *Token
+}
+
+// 返回第i个字元所在的合并区间，没有约束时返回nil
+func (wc *wordConstraints) merge(i int) *wordMerge {
+	if wc == nil {
+		return nil
+	}
+	return wc.merges[i]
+}
+
+// 判断从第start个字元到第end个字元的候选分词是否跨越了分词边界
+func (wc *wordConstraints) allows(start, end int) bool {
+	if wc == nil {
+		return true
+	}
+	for i := start + 1; i <= end; i++ {
+		if wc.boundaries[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// 在字节位置cuts（从小到大排列）处切开字元，返回切开后的字元和每个字元的起始
+// 字节位置，字节位置的最后一个元素为文本的总长度
+func splitWordsAt(words []Text, cuts []int) ([]Text, []int) {
+	output := make([]Text, 0, len(words)+len(cuts))
+	offsets := make([]int, 0, len(words)+len(cuts)+1)
+	position := 0
+	iCut := 0
+	for _, word := range words {
+		end := position + len(word)
+		for iCut < len(cuts) && cuts[iCut] <= position {
+			iCut++
+		}
+		for iCut < len(cuts) && cuts[iCut] < end {
+			output = append(output, word[:cuts[iCut]-position])
+			offsets = append(offsets, position)
+			word = word[cuts[iCut]-position:]
+			position = cuts[iCut]
+			iCut++
+		}
+		output = append(output, word)
+		offsets = append(offsets, position)
+		position = end
+	}
+	offsets = append(offsets, position)
+	return output, offsets
+}
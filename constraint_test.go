@@ -0,0 +1,38 @@
+package sego
+
+import (
+	"testing"
+)
+
+func TestSegmentWithConstraints(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	text := []byte("中国有十三亿人口")
+
+	expect(t, "中国/ 有/p3 十三亿/ 人口/p12 ", SegmentsToString(
+		seg.SegmentWithConstraints(text, Constraints{}), false))
+
+	// 在"十三"和"亿"之间切开
+	expect(t, "中国/ 有/p3 十三/p10 亿/p5 人口/p12 ", SegmentsToString(
+		seg.SegmentWithConstraints(text, Constraints{Splits: []int{15}}), false))
+
+	// 合并词典中的分词
+	expect(t, "中/p1 国有/p9 十三亿/ 人口/p12 ", SegmentsToString(
+		seg.SegmentWithConstraints(text, Constraints{Merges: []Span{{3, 9}}}), false))
+
+	// 合并词典中没有的分词，区间内部的切分位置、和前面区间重叠的区间以及不在
+	// 字符边界上的位置被忽略
+	segments := seg.SegmentWithConstraints(text, Constraints{
+		Merges: []Span{{6, 12}, {9, 15}},
+		Splits: []int{9, 1},
+	})
+	expect(t, "中国/ 有十/x 三/ 亿/p5 人口/p12 ", SegmentsToString(segments, false))
+	expect(t, "6", segments[1].Start())
+	expect(t, "12", segments[1].End())
+
+	// 在英文单词中间切开
+	segments = seg.SegmentWithConstraints([]byte("GitHub中国"), Constraints{Splits: []int{3}})
+	expect(t, "git/x hub/x 中国/ ", SegmentsToString(segments, false))
+	expect(t, "3", segments[1].Start())
+	expect(t, "6", segments[1].End())
+}
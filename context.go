@@ -0,0 +1,72 @@
+package sego
+
+import (
+	"context"
+	"errors"
+)
+
+const (
+	// 分词时每处理多少个字元检查一次ctx是否被取消
+	cancelCheckInterval = 1024
+)
+
+var (
+	// 文本超过Limits.MaxTextBytes
+	ErrTextTooLong = errors.New("sego: text too long")
+
+	// 分词数目超过Limits.MaxSegments
+	ErrTooManySegments = errors.New("sego: too many segments")
+)
+
+// 分词的资源限制，用于处理不可信的输入。零值表示没有限制。
+//
+// 限制只对SegmentContext和基于它的SegmentBatch生效，Segment等其它函数不受限制。
+type Limits struct {
+	// 文本的最大字节数
+	MaxTextBytes int
+
+	// 分词结果的最大分词数目
+	MaxSegments int
+}
+
+// 设置分词的资源限制，应在开始分词之前设置
+func (seg *Segmenter) SetLimits(limits Limits) {
+	seg.limits = limits
+}
+
+// 返回分词的资源限制
+func (seg *Segmenter) Limits() Limits {
+	return seg.limits
+}
+
+// 对文本分词，分词结果和Segment相同
+//
+// 分词过程中定期检查ctx，ctx被取消时停止分词并返回ctx.Err()。文本超过
+// MaxTextBytes时不分词直接返回ErrTextTooLong，分词数目超过MaxSegments时返回
+// ErrTooManySegments。
+func (seg *Segmenter) SegmentContext(ctx context.Context, bytes []byte) ([]Segment, error) {
+	if seg.limits.MaxTextBytes > 0 && len(bytes) > seg.limits.MaxTextBytes {
+		return nil, ErrTextTooLong
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// 处理特殊情况
+	if len(bytes) == 0 {
+		return []Segment{}, nil
+	}
+
+	ws := workspacePool.Get().(*Workspace)
+	defer func() {
+		ws.release()
+		workspacePool.Put(ws)
+	}()
+	if err := ws.segment(ctx, seg, bytes, false); err != nil {
+		return nil, err
+	}
+	if seg.limits.MaxSegments > 0 && ws.lattice.bestPathLength() > seg.limits.MaxSegments {
+		return nil, ErrTooManySegments
+	}
+	return ws.lattice.appendBestPath(nil), nil
+}
@@ -0,0 +1,57 @@
+package sego
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestSegmentContext(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	text := []byte("中国有十三亿人口")
+	segments, err := seg.SegmentContext(context.Background(), text)
+	expect(t, "<nil>", err)
+	expect(t, SegmentsToString(seg.Segment(text), false), SegmentsToString(segments, false))
+	segments, err = seg.SegmentContext(context.Background(), nil)
+	expect(t, "<nil>", err)
+	expect(t, "0", len(segments))
+
+	// 长文本在分词过程中被取消
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = seg.SegmentContext(ctx, bytes.Repeat(text, 1000))
+	expect(t, "context canceled", err)
+
+	// 资源限制
+	seg.SetLimits(Limits{MaxTextBytes: len(text)})
+	_, err = seg.SegmentContext(context.Background(), text)
+	expect(t, "<nil>", err)
+	_, err = seg.SegmentContext(context.Background(), append(text, '!'))
+	expect(t, ErrTextTooLong.Error(), err)
+
+	seg.SetLimits(Limits{MaxSegments: 4})
+	segments, err = seg.SegmentContext(context.Background(), text)
+	expect(t, "<nil>", err)
+	expect(t, "4", len(segments))
+	_, err = seg.SegmentContext(context.Background(), append(text, '!'))
+	expect(t, ErrTooManySegments.Error(), err)
+
+	_, err = seg.SegmentBatch(context.Background(), [][]byte{text, append(text, '!'), text}, 2)
+	expect(t, ErrTooManySegments.Error(), err)
+}
+
+func TestBuildLatticeCancel(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	// 每处理cancelCheckInterval个字元检查一次
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var l lattice
+	words := splitTextToWords(bytes.Repeat([]byte("中"), cancelCheckInterval*2))
+	expect(t, "context canceled", seg.buildLattice(ctx, &l, words, false, nil))
+	expect(t, "<nil>", seg.buildLattice(context.Background(), &l, words, false, nil))
+	expect(t, "<nil>", seg.buildLattice(nil, &l, words, false, nil))
+}
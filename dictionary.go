@@ -53,6 +53,15 @@ func (dict *Dictionary) addToken(token Token) {
 	}
 }
 
+// 在词典中查找文本为text的分词，英文不区分大小写，找不到时返回nil
+func (dict *Dictionary) Lookup(text []byte) *Token {
+	value, err := dict.trie.Get(textSliceToBytes(splitTextToWords(text)))
+	if err != nil {
+		return nil
+	}
+	return &dict.tokens[value]
+}
+
 // 在词典中查找和字元组words可以前缀匹配的所有分词
 // 返回值为找到的分词数
 func (dict *Dictionary) lookupTokens(words []Text, tokens []*Token) (numOfTokens int) {
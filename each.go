@@ -0,0 +1,26 @@
+package sego
+
+// 对文本分词，并按顺序对每个分词调用fn，fn返回false时停止
+//
+// 和Segment相比，Each从缓冲池中取用Workspace，分词完毕后归还，因此大量分词时
+// 几乎不产生内存分配，适合只需遍历一次分词结果的场景，比如建立索引。分词结果和
+// Segment相同，但传给fn的分词只在fn调用期间有效，需要保存分词结果时请使用Segment。
+func (seg *Segmenter) Each(bytes []byte, fn func(Segment) bool) {
+	// 处理特殊情况
+	if len(bytes) == 0 {
+		return
+	}
+
+	ws := workspacePool.Get().(*Workspace)
+	ws.segment(nil, seg, bytes, true)
+	ws.segments = ws.lattice.appendBestPath(ws.segments[:0])
+
+	for _, segment := range ws.segments {
+		if !fn(segment) {
+			break
+		}
+	}
+
+	ws.release()
+	workspacePool.Put(ws)
+}
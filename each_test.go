@@ -0,0 +1,43 @@
+package sego
+
+import (
+	"testing"
+)
+
+func TestEach(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	text := []byte("中国有十三亿人口")
+
+	output := []Segment{}
+	seg.Each(text, func(segment Segment) bool {
+		output = append(output, segment)
+		return true
+	})
+	expect(t, "中国/ 有/p3 十三亿/ 人口/p12 ", SegmentsToString(output, false))
+	expect(t, "18", output[3].start)
+	expect(t, "24", output[3].end)
+
+	// 提前停止
+	output = output[:0]
+	seg.Each(text, func(segment Segment) bool {
+		output = append(output, segment)
+		return len(output) < 2
+	})
+	expect(t, "中国/ 有/p3 ", SegmentsToString(output, false))
+
+	// 重复使用缓冲区时结果不变
+	for i := 0; i < 3; i++ {
+		words := []string{}
+		seg.Each([]byte("GitHub人口"), func(segment Segment) bool {
+			words = append(words, segment.Token().Text())
+			return true
+		})
+		expect(t, "[github 人口]", words)
+	}
+
+	seg.Each(nil, func(segment Segment) bool {
+		t.Error("空文本不应有分词")
+		return true
+	})
+}
@@ -0,0 +1,258 @@
+/*
+
+evaluation包用标准分词语料评测sego的分词准确率。
+
+标准语料采用SIGHAN bakeoff格式：每行一句，分词之间用空白分隔，比如
+
+	中国 有 十三亿 人口
+
+评测时将每行的分词拼接成原文交给Segmenter.Segment，再按字节位置比较输出和
+标准分词，得到准确率、召回率、F1以及未登录词（OOV）和登录词（IV）的召回率。
+相邻两个分词的衔接处都是拉丁字母或数字时（比如"New York"）拼接时中间保留一个
+空格，否则分词器会把它们当作一个英文词；分词器输出的这些空格不参与评测。
+
+*/
+package evaluation
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/huichen/sego"
+)
+
+// 评测参数
+type Options struct {
+	// 登录词表，通常为训练语料中出现过的分词。为nil时以分词器的词典作为登录词表。
+	Vocabulary map[string]bool
+
+	// 最多记录多少个分词结果和标准不同的句子，为零时不记录，为负数时全部记录
+	MaxDiffs int
+}
+
+// 评测结果
+type Result struct {
+	// 评测的句子数
+	NumSentences int
+
+	// 标准分词数、输出分词数和输出中正确的分词数
+	NumGoldWords    int
+	NumOutputWords  int
+	NumCorrectWords int
+
+	// 标准分词中未登录词数和其中被正确切分的数目
+	NumOOVWords        int
+	NumCorrectOOVWords int
+
+	// 标准分词中登录词数和其中被正确切分的数目
+	NumIVWords        int
+	NumCorrectIVWords int
+
+	// 分词结果和标准不同的句子
+	Diffs []Diff
+}
+
+// 一个分词结果和标准不同的句子
+type Diff struct {
+	// 句子在标准语料中的行号，从1开始
+	Line int
+
+	// 标准分词
+	Gold []string
+
+	// 分词器输出的分词
+	Output []string
+}
+
+// 用标准语料评测分词器
+func Evaluate(segmenter *sego.Segmenter, gold io.Reader, options Options) (*Result, error) {
+	result := &Result{}
+	reader := bufio.NewReader(gold)
+	for line := 1; ; line++ {
+		text, err := reader.ReadString('\n')
+		if words := strings.Fields(text); len(words) > 0 {
+			result.evaluateSentence(segmenter, line, words, options)
+		}
+		if err == io.EOF {
+			return result, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// 评测一个句子
+func (result *Result) evaluateSentence(
+	segmenter *sego.Segmenter, line int, goldWords []string, options Options) {
+	// 拼接原文，记录每个标准分词的起始字节位置
+	var text []byte
+	starts := make([]int, len(goldWords))
+	for i, word := range goldWords {
+		if i > 0 && needsSeparator(goldWords[i-1], word) {
+			text = append(text, ' ')
+		}
+		starts[i] = len(text)
+		text = append(text, word...)
+	}
+	segments := []sego.Segment{}
+	for _, segment := range segmenter.Segment(text) {
+		// 去掉拼接时加入的空格
+		if string(text[segment.Start():segment.End()]) != " " {
+			segments = append(segments, segment)
+		}
+	}
+
+	// 输出分词的字节位置
+	outputSpans := make(map[[2]int]bool, len(segments))
+	for _, segment := range segments {
+		outputSpans[[2]int{segment.Start(), segment.End()}] = true
+	}
+
+	// 逐个比较标准分词
+	numCorrect := 0
+	for i, word := range goldWords {
+		correct := outputSpans[[2]int{starts[i], starts[i] + len(word)}]
+		if correct {
+			numCorrect++
+		}
+		if isInVocabulary(segmenter, word, options.Vocabulary) {
+			result.NumIVWords++
+			if correct {
+				result.NumCorrectIVWords++
+			}
+		} else {
+			result.NumOOVWords++
+			if correct {
+				result.NumCorrectOOVWords++
+			}
+		}
+	}
+
+	result.NumSentences++
+	result.NumGoldWords += len(goldWords)
+	result.NumOutputWords += len(segments)
+	result.NumCorrectWords += numCorrect
+
+	// 记录分词结果不同的句子
+	if numCorrect == len(goldWords) && len(segments) == len(goldWords) {
+		return
+	}
+	if options.MaxDiffs >= 0 && len(result.Diffs) >= options.MaxDiffs {
+		return
+	}
+	output := make([]string, len(segments))
+	for i, segment := range segments {
+		output[i] = string(text[segment.Start():segment.End()])
+	}
+	result.Diffs = append(result.Diffs, Diff{Line: line, Gold: goldWords, Output: output})
+}
+
+// 拼接相邻的两个分词时是否需要以空格分隔，即衔接处是否都是拉丁字母或数字。
+// 和sego划分字元的规则相同，中日韩文字不算在内。
+func needsSeparator(previous, next string) bool {
+	last, lastSize := utf8.DecodeLastRuneInString(previous)
+	first, firstSize := utf8.DecodeRuneInString(next)
+	return isAlphanumeric(last, lastSize) && isAlphanumeric(first, firstSize)
+}
+
+func isAlphanumeric(r rune, size int) bool {
+	return size <= 2 && (unicode.IsLetter(r) || unicode.IsNumber(r))
+}
+
+// 判断分词是否为登录词
+func isInVocabulary(segmenter *sego.Segmenter, word string, vocabulary map[string]bool) bool {
+	if vocabulary != nil {
+		return vocabulary[word]
+	}
+	return segmenter.Dictionary().Lookup([]byte(word)) != nil
+}
+
+// 准确率：输出中正确的分词数/输出分词数
+func (result *Result) Precision() float64 {
+	return ratio(result.NumCorrectWords, result.NumOutputWords)
+}
+
+// 召回率：输出中正确的分词数/标准分词数
+func (result *Result) Recall() float64 {
+	return ratio(result.NumCorrectWords, result.NumGoldWords)
+}
+
+// 准确率和召回率的调和平均
+func (result *Result) F1() float64 {
+	p, r := result.Precision(), result.Recall()
+	if p+r == 0 {
+		return 0
+	}
+	return 2 * p * r / (p + r)
+}
+
+// 未登录词的召回率
+func (result *Result) OOVRecall() float64 {
+	return ratio(result.NumCorrectOOVWords, result.NumOOVWords)
+}
+
+// 登录词的召回率
+func (result *Result) IVRecall() float64 {
+	return ratio(result.NumCorrectIVWords, result.NumIVWords)
+}
+
+// 未登录词占标准分词的比例
+func (result *Result) OOVRate() float64 {
+	return ratio(result.NumOOVWords, result.NumGoldWords)
+}
+
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
+
+// 输出评测结果的摘要
+func (result *Result) String() string {
+	return fmt.Sprintf(
+		"句子数 %d\n标准分词数 %d\n输出分词数 %d\n正确分词数 %d\n"+
+			"准确率 %.4f\n召回率 %.4f\nF1 %.4f\n"+
+			"未登录词率 %.4f\n未登录词召回率 %.4f\n登录词召回率 %.4f\n",
+		result.NumSentences, result.NumGoldWords, result.NumOutputWords, result.NumCorrectWords,
+		result.Precision(), result.Recall(), result.F1(),
+		result.OOVRate(), result.OOVRecall(), result.IVRecall())
+}
+
+// 输出分词结果和标准不同的句子，每个句子三行：
+//
+//	行号
+//	- 标准分词
+//	+ 分词器输出
+func (result *Result) WriteDiffs(writer io.Writer) error {
+	bufWriter := bufio.NewWriter(writer)
+	for _, diff := range result.Diffs {
+		_, err := fmt.Fprintf(bufWriter, "%d\n- %s\n+ %s\n",
+			diff.Line, strings.Join(diff.Gold, " "), strings.Join(diff.Output, " "))
+		if err != nil {
+			return err
+		}
+	}
+	return bufWriter.Flush()
+}
+
+// 从文件中读入登录词表，每行一个分词，也可以直接使用SIGHAN的训练语料
+func ReadVocabulary(reader io.Reader) (map[string]bool, error) {
+	vocabulary := make(map[string]bool)
+	bufReader := bufio.NewReader(reader)
+	for {
+		line, err := bufReader.ReadString('\n')
+		for _, word := range strings.Fields(line) {
+			vocabulary[word] = true
+		}
+		if err == io.EOF {
+			return vocabulary, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package evaluation
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/huichen/sego"
+)
+
+func expect(t *testing.T, expect string, actual interface{}) {
+	actualString := fmt.Sprint(actual)
+	if expect != actualString {
+		t.Errorf("期待值=\"%s\", 实际=\"%s\"", expect, actualString)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	var seg sego.Segmenter
+	seg.LoadDictionary("../testdata/test_dict1.txt,../testdata/test_dict2.txt")
+
+	// 分词器输出"中国 有 十三亿 人口"
+	gold := "中国 有 十三亿 人口\n\n中国 有 十三 亿 人口\n"
+	result, err := Evaluate(&seg, strings.NewReader(gold), Options{MaxDiffs: -1})
+	expect(t, "<nil>", err)
+	expect(t, "2", result.NumSentences)
+	expect(t, "9", result.NumGoldWords)
+	expect(t, "8", result.NumOutputWords)
+	expect(t, "7", result.NumCorrectWords)
+	expect(t, "0.875", fmt.Sprintf("%.3f", result.Precision()))
+	expect(t, "0.778", fmt.Sprintf("%.3f", result.Recall()))
+	expect(t, "0.824", fmt.Sprintf("%.3f", result.F1()))
+
+	// "十三亿"和"亿"都在词典中，所有分词都是登录词
+	expect(t, "0", result.NumOOVWords)
+	expect(t, "0.778", fmt.Sprintf("%.3f", result.IVRecall()))
+
+	var buf bytes.Buffer
+	expect(t, "<nil>", result.WriteDiffs(&buf))
+	expect(t, "3\n- 中国 有 十三 亿 人口\n+ 中国 有 十三亿 人口\n", buf.String())
+
+	// 自定义登录词表
+	result, _ = Evaluate(&seg, strings.NewReader(gold),
+		Options{Vocabulary: map[string]bool{"中国": true, "有": true, "人口": true}})
+	expect(t, "3", result.NumOOVWords)
+	expect(t, "1", result.NumCorrectOOVWords)
+	expect(t, "0", len(result.Diffs))
+}
+
+func TestEvaluateMixedScript(t *testing.T) {
+	var seg sego.Segmenter
+	seg.LoadDictionary("../testdata/test_dict1.txt,../testdata/test_dict2.txt")
+
+	// "New"和"York"之间保留空格，"York"和"有"之间不加空格
+	result, err := Evaluate(&seg, strings.NewReader("New York 有 1000 万 人口\n"), Options{MaxDiffs: -1})
+	expect(t, "<nil>", err)
+	expect(t, "6", result.NumGoldWords)
+	expect(t, "6", result.NumOutputWords)
+	expect(t, "6", result.NumCorrectWords)
+	expect(t, "0", len(result.Diffs))
+}
@@ -0,0 +1,181 @@
+package sego
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// 分词网格中的一个候选分词
+type Candidate struct {
+	// 候选分词在文本中的起始和结束字节位置
+	Start, End int
+
+	// 候选分词的信息，词典中没有的字元和英文词对应词性为"x"的伪分词
+	Token *Token
+
+	// 候选分词本身的路径长度。使用Scorer时为前一个分词是Prev时的路径长度。
+	Distance float32
+
+	// 从文本开头到该候选分词（包括该分词）的最短路径值
+	Cost float32
+
+	// 最短路径上前一个候选分词在Candidates中的下标，-1表示该分词位于文本开头
+	Prev int
+
+	// 是否在最终的分词结果中
+	OnPath bool
+
+	// 候选分词对应的分词，包括各种单位的位置
+	segment Segment
+}
+
+// 返回候选分词对应的分词，比如用于输出文本中所有可能的分词
+func (c *Candidate) Segment() Segment {
+	return c.segment
+}
+
+// 一段文本的完整分词网格，用于分析分词结果的成因
+type Explanation struct {
+	// 所有候选分词，按起始位置排序，起始位置相同的按长度排序
+	Candidates []Candidate
+
+	// 分词结果，和Segment的结果相同
+	Segments []Segment
+
+	// 分词结果的路径值，越小越好
+	Cost float32
+}
+
+// 对文本分词，并返回包括所有候选分词的分词网格
+//
+// Explain比Segment慢得多，只应用于调试。
+func (seg *Segmenter) Explain(bytes []byte) *Explanation {
+	explanation := &Explanation{Candidates: []Candidate{}, Segments: []Segment{}}
+	if len(bytes) == 0 {
+		return explanation
+	}
+
+	text := splitTextToWords(bytes)
+	l := lattice{keepAll: true}
+	seg.buildLattice(nil, &l, text, false, nil)
+	explanation.Segments = l.appendBestPath(nil)
+	explanation.Cost = l.jumpers[l.bestAt(len(text)-1)].minDistance
+
+	// 每个字元的起始位置
+	positions := make([]offset, len(text)+1)
+	for i, word := range text {
+		positions[i+1] = positions[i]
+		positions[i+1].add(word)
+	}
+
+	// 候选分词按起始字元和长度排序，并记录跳转下标到候选分词下标的对应
+	sorted := make([]int, len(l.jumpers))
+	for i := range sorted {
+		sorted[i] = i
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := &l.jumpers[sorted[i]], &l.jumpers[sorted[j]]
+		if a.start != b.start {
+			return a.start < b.start
+		}
+		return len(a.token.text) < len(b.token.text)
+	})
+	order := make([]int, len(l.jumpers))
+	explanation.Candidates = make([]Candidate, len(l.jumpers))
+	for i, index := range sorted {
+		order[index] = i
+		j := &l.jumpers[index]
+		start, end := positions[j.start], positions[int(j.start)+len(j.token.text)]
+		explanation.Candidates[i] = Candidate{
+			Start: start.bytes,
+			End:   end.bytes,
+			Token: j.token,
+			segment: Segment{
+				start: start.bytes, end: end.bytes,
+				runeStart: start.runes, runeEnd: end.runes,
+				utf16Start: start.utf16, utf16End: end.utf16,
+				token: j.token,
+			},
+		}
+	}
+	for index, j := range l.jumpers {
+		candidate := &explanation.Candidates[order[index]]
+		candidate.Cost = j.minDistance
+		candidate.Distance = j.minDistance
+		candidate.Prev = -1
+		if j.prev != -1 {
+			candidate.Prev = order[j.prev]
+			candidate.Distance -= l.jumpers[j.prev].minDistance
+		}
+	}
+	for index := l.bestAt(len(text) - 1); index != -1; index = l.jumpers[index].prev {
+		explanation.Candidates[order[index]].OnPath = true
+	}
+	return explanation
+}
+
+// 以文本表格的形式输出所有候选分词，最终分词结果中的候选分词以*标出
+func (e *Explanation) WriteText(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+	fmt.Fprintf(writer, "  %-12s %-16s %-4s %10s %10s %10s %6s\n",
+		"位置", "分词", "词性", "频率", "距离", "累计", "前驱")
+	for i, candidate := range e.Candidates {
+		mark := " "
+		if candidate.OnPath {
+			mark = "*"
+		}
+		fmt.Fprintf(writer, "%s %-12s %-16s %-4s %10d %10.4f %10.4f %6d\n", mark,
+			fmt.Sprintf("%d:[%d,%d)", i, candidate.Start, candidate.End),
+			candidate.Token.Text(), candidate.Token.Pos(), candidate.Token.Frequency(),
+			candidate.Distance, candidate.Cost, candidate.Prev)
+	}
+	fmt.Fprintf(writer, "分词结果: %s\n", SegmentsToString(e.Segments, false))
+	fmt.Fprintf(writer, "路径值: %.4f\n", e.Cost)
+	return writer.Flush()
+}
+
+// 以Graphviz DOT格式输出分词网格，可用dot -Tsvg等命令绘制成图
+//
+// 文本中的字节位置为节点，候选分词为从起始位置指向结束位置的边，边上标出分词、
+// 词性和路径长度，最终分词结果中的候选分词以红色粗线标出。
+func (e *Explanation) WriteDot(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+	fmt.Fprintln(writer, "digraph lattice {")
+	fmt.Fprintln(writer, "\trankdir=LR;")
+	fmt.Fprintln(writer, "\tnode [shape=circle];")
+
+	// 只输出候选分词的端点
+	nodes := map[int]bool{}
+	var positions []int
+	for _, candidate := range e.Candidates {
+		for _, position := range []int{candidate.Start, candidate.End} {
+			if !nodes[position] {
+				nodes[position] = true
+				positions = append(positions, position)
+			}
+		}
+	}
+	for _, position := range positions {
+		fmt.Fprintf(writer, "\tn%d [label=\"%d\"];\n", position, position)
+	}
+
+	for _, candidate := range e.Candidates {
+		style := ""
+		if candidate.OnPath {
+			style = ", color=red, penwidth=2"
+		}
+		fmt.Fprintf(writer, "\tn%d -> n%d [label=\"%s/%s\\n%.2f\"%s];\n",
+			candidate.Start, candidate.End, dotEscape(candidate.Token.Text()),
+			dotEscape(candidate.Token.Pos()), candidate.Distance, style)
+	}
+	fmt.Fprintln(writer, "}")
+	return writer.Flush()
+}
+
+// 转义DOT字符串中的特殊字符
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`).Replace(s)
+}
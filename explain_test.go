@@ -0,0 +1,61 @@
+package sego
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	text := []byte("中国有十三亿人口")
+	explanation := seg.Explain(text)
+	expect(t, SegmentsToString(seg.Segment(text), false), SegmentsToString(explanation.Segments, false))
+
+	onPath := ""
+	var cost float32
+	for i, candidate := range explanation.Candidates {
+		if i > 0 && candidate.Start < explanation.Candidates[i-1].Start {
+			t.Fatal("候选分词没有按起始位置排序")
+		}
+		expect(t, candidate.Token.Text(), string(text[candidate.Start:candidate.End]))
+		if candidate.OnPath {
+			onPath += candidate.Token.Text() + " "
+			cost += candidate.Distance
+		}
+		if candidate.Prev != -1 &&
+			explanation.Candidates[candidate.Prev].End != candidate.Start {
+			t.Fatal("前驱分词位置错误")
+		}
+	}
+	expect(t, "中国 有 十三亿 人口 ", onPath)
+	expect(t, fmt.Sprint(explanation.Cost), cost)
+
+	// 所有候选分词，包括不在最终结果中的
+	candidates := []string{}
+	for _, candidate := range explanation.Candidates {
+		candidates = append(candidates, candidate.Token.Text())
+	}
+	expect(t, "中 中国 国 国有 有 十 十三 十三亿 三 亿 人 人口 口", strings.Join(candidates, " "))
+	last := explanation.Candidates[len(explanation.Candidates)-1].Segment()
+	expect(t, "口 21 24 7 8", fmt.Sprint(last.Token().Text(), " ", last.Start(), " ", last.End(),
+		" ", last.RuneStart(), " ", last.RuneEnd()))
+
+	var buf bytes.Buffer
+	expect(t, "<nil>", explanation.WriteText(&buf))
+	if !strings.Contains(buf.String(), "* 1:[0,6)") {
+		t.Error(buf.String())
+	}
+	buf.Reset()
+	expect(t, "<nil>", explanation.WriteDot(&buf))
+	if !strings.HasPrefix(buf.String(), "digraph lattice {") ||
+		!strings.Contains(buf.String(), `n6 -> n9 [label="有/p3\n3.03", color=red`) {
+		t.Error(buf.String())
+	}
+
+	explanation = seg.Explain(nil)
+	expect(t, "0", len(explanation.Candidates))
+}
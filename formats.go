@@ -0,0 +1,295 @@
+package sego
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 标注语料中的一个词，用于读入人工标注的分词结果
+type Word struct {
+	Text string
+
+	// 词性，语料中没有词性标注时为空
+	Pos string
+}
+
+// 以下WriteXXX函数每次输出一个句子的分词结果，空白字符组成的分词不输出。text为
+// 句子的原文，segs为其分词结果，分词按其在text中的位置输出原文，因此保留英文的
+// 大小写。对应的ReadXXX函数读入整个语料，每个句子返回一个[]Word。
+
+// 以空格分隔的格式输出分词，一行一个句子，即PKU、MSR等分词评测语料的格式：
+//
+//	中国 有 十三亿 人口
+func WriteSpaceSeparated(w io.Writer, text []byte, segs []Segment) error {
+	writer := bufio.NewWriter(w)
+	first := true
+	for _, seg := range segs {
+		if isSpaceToken(seg.token) {
+			continue
+		}
+		if !first {
+			writer.WriteByte(' ')
+		}
+		first = false
+		writer.Write(text[seg.start:seg.end])
+	}
+	writer.WriteByte('\n')
+	return writer.Flush()
+}
+
+// 读入以空白分隔的分词语料，一行一个句子，空行被忽略
+func ReadSpaceSeparated(r io.Reader) ([][]Word, error) {
+	return readLines(r, func(field string) Word {
+		return Word{Text: field}
+	})
+}
+
+// 以"分词/词性"的格式输出分词，分词之间以空格分隔，一行一个句子，即人民日报
+// 标注语料的格式：
+//
+//	中国/ns 有/v 十三亿/m 人口/n
+func WriteWordPos(w io.Writer, text []byte, segs []Segment) error {
+	writer := bufio.NewWriter(w)
+	first := true
+	for _, seg := range segs {
+		if isSpaceToken(seg.token) {
+			continue
+		}
+		if !first {
+			writer.WriteByte(' ')
+		}
+		first = false
+		writer.Write(text[seg.start:seg.end])
+		writer.WriteByte('/')
+		writer.WriteString(seg.token.pos)
+	}
+	writer.WriteByte('\n')
+	return writer.Flush()
+}
+
+// 读入"分词/词性"格式的语料，一行一个句子，空行被忽略。没有"/"的分词词性为空；
+// 人民日报语料中的复合词标记"[中国/ns 人民/n]nt"中的方括号和复合词词性被忽略。
+func ReadWordPos(r io.Reader) ([][]Word, error) {
+	return readLines(r, func(field string) Word {
+		if strings.HasPrefix(field, "[") && len(field) > 1 {
+			field = field[1:]
+		}
+		slash := strings.LastIndex(field, "/")
+		if slash <= 0 {
+			return Word{Text: field}
+		}
+		word := Word{Text: field[:slash], Pos: field[slash+1:]}
+		if bracket := strings.Index(word.Pos, "]"); bracket >= 0 {
+			word.Pos = word.Pos[:bracket]
+		}
+		return word
+	})
+}
+
+// 以BMES字标注的格式输出分词，每行一个字及其标注，以制表符分隔，句子之间以
+// 空行分隔。B、M、E分别表示多字分词的首字、中间字和尾字，S表示单字分词：
+//
+//	中	B
+//	国	E
+//	有	S
+//
+// 英文等字母组成的分词也按字符标注。
+func WriteBMES(w io.Writer, text []byte, segs []Segment) error {
+	writer := bufio.NewWriter(w)
+	for _, seg := range segs {
+		if isSpaceToken(seg.token) {
+			continue
+		}
+		word := string(text[seg.start:seg.end])
+		numRunes := utf8.RuneCountInString(word)
+		i := 0
+		for _, r := range word {
+			tag := byte('M')
+			switch {
+			case numRunes == 1:
+				tag = 'S'
+			case i == 0:
+				tag = 'B'
+			case i == numRunes-1:
+				tag = 'E'
+			}
+			writer.WriteRune(r)
+			writer.WriteByte('\t')
+			writer.WriteByte(tag)
+			writer.WriteByte('\n')
+			i++
+		}
+	}
+	writer.WriteByte('\n')
+	return writer.Flush()
+}
+
+// 读入BMES字标注格式的语料。每行第一列为字，最后一列为标注，标注可以带有
+// "-词性"后缀，比如"B-ns"，此时该词性作为分词的词性。标注不完整时（比如B之后
+// 直接是S）在不完整处结束分词。
+func ReadBMES(r io.Reader) ([][]Word, error) {
+	sentences := [][]Word{}
+	sentence := []Word{}
+	var word strings.Builder
+	pos := ""
+	endWord := func() {
+		if word.Len() > 0 {
+			sentence = append(sentence, Word{Text: word.String(), Pos: pos})
+			word.Reset()
+		}
+	}
+	endSentence := func() {
+		endWord()
+		if len(sentence) > 0 {
+			sentences = append(sentences, sentence)
+			sentence = []Word{}
+		}
+	}
+
+	scanner := newLineScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			endSentence()
+			continue
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("sego: invalid BMES line %q", scanner.Text())
+		}
+		tag, tagPos := fields[len(fields)-1], ""
+		if dash := strings.Index(tag, "-"); dash >= 0 {
+			tag, tagPos = tag[:dash], tag[dash+1:]
+		}
+		if tag == "B" || tag == "S" {
+			endWord()
+			pos = tagPos
+		} else if tagPos != "" {
+			pos = tagPos
+		}
+		word.WriteString(fields[0])
+		if tag == "E" || tag == "S" {
+			endWord()
+		}
+	}
+	endSentence()
+	return sentences, scanner.Err()
+}
+
+// 以CoNLL-U格式输出分词，句子之前为"# text = "原文注释，之后为空行。分词的词性
+// 输出在XPOS列，其后没有空白时MISC列为SpaceAfter=No，其余各列为"_"：
+//
+//	# text = 中国有十三亿人口
+//	1	中国	_	_	ns	_	_	_	_	SpaceAfter=No
+//	...
+func WriteCoNLLU(w io.Writer, text []byte, segs []Segment) error {
+	writer := bufio.NewWriter(w)
+	writer.WriteString("# text = ")
+	for _, r := range string(text) {
+		// 注释中不能有换行
+		if r == '\n' || r == '\r' {
+			r = ' '
+		}
+		writer.WriteRune(r)
+	}
+	writer.WriteByte('\n')
+
+	id := 0
+	for i, seg := range segs {
+		if isSpaceToken(seg.token) {
+			continue
+		}
+		id++
+		writer.WriteString(strconv.Itoa(id))
+		writer.WriteByte('\t')
+		writer.Write(text[seg.start:seg.end])
+		writer.WriteString("\t_\t_\t")
+		if seg.token.pos == "" {
+			writer.WriteByte('_')
+		} else {
+			writer.WriteString(seg.token.pos)
+		}
+		writer.WriteString("\t_\t_\t_\t_\t")
+		if i+1 < len(segs) && isSpaceToken(segs[i+1].token) {
+			writer.WriteString("_\n")
+		} else {
+			writer.WriteString("SpaceAfter=No\n")
+		}
+	}
+	writer.WriteByte('\n')
+	return writer.Flush()
+}
+
+// 读入CoNLL-U格式的语料，分词的词性取XPOS列，XPOS为"_"时取UPOS列。注释、多词
+// 单元（ID为"1-2"）和空节点（ID为"1.1"）被忽略。
+func ReadCoNLLU(r io.Reader) ([][]Word, error) {
+	sentences := [][]Word{}
+	sentence := []Word{}
+	scanner := newLineScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			if len(sentence) > 0 {
+				sentences = append(sentences, sentence)
+				sentence = []Word{}
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 10 {
+			return nil, fmt.Errorf("sego: invalid CoNLL-U line %q", line)
+		}
+		if strings.ContainsAny(fields[0], "-.") {
+			continue
+		}
+		word := Word{Text: fields[1], Pos: fields[4]}
+		if word.Pos == "_" {
+			word.Pos = fields[3]
+		}
+		if word.Pos == "_" {
+			word.Pos = ""
+		}
+		sentence = append(sentence, word)
+	}
+	if len(sentence) > 0 {
+		sentences = append(sentences, sentence)
+	}
+	return sentences, scanner.Err()
+}
+
+// 是否为只包含空白字符的分词
+func isSpaceToken(token *Token) bool {
+	for _, word := range token.text {
+		for _, r := range string(word) {
+			if !unicode.IsSpace(r) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// 逐行读入语料，每行以空白分隔为多个分词，由parse转换为Word
+func readLines(r io.Reader, parse func(field string) Word) ([][]Word, error) {
+	sentences := [][]Word{}
+	scanner := newLineScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		sentence := make([]Word, len(fields))
+		for i, field := range fields {
+			sentence[i] = parse(field)
+		}
+		sentences = append(sentences, sentence)
+	}
+	return sentences, scanner.Err()
+}
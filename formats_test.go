@@ -0,0 +1,75 @@
+package sego
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFormats(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	texts := [][]byte{[]byte("中国有十三亿人口"), []byte("Made in 中国！")}
+	sentences := [][]Segment{seg.Segment(texts[0]), seg.Segment(texts[1])}
+
+	var buf bytes.Buffer
+	for i, sentence := range sentences {
+		expect(t, "<nil>", WriteSpaceSeparated(&buf, texts[i], sentence))
+	}
+	expect(t, "中国 有 十三亿 人口\nMade in 中国 ！\n", buf.String())
+	words, err := ReadSpaceSeparated(&buf)
+	expect(t, "<nil>", err)
+	expect(t, "[[{中国 } {有 } {十三亿 } {人口 }] [{Made } {in } {中国 } {！ }]]", words)
+
+	buf.Reset()
+	for i, sentence := range sentences {
+		expect(t, "<nil>", WriteWordPos(&buf, texts[i], sentence))
+	}
+	expect(t, "中国/ 有/p3 十三亿/ 人口/p12\nMade/x in/x 中国/ ！/x\n", buf.String())
+	words, err = ReadWordPos(&buf)
+	expect(t, "<nil>", err)
+	expect(t, "[[{中国 } {有 p3} {十三亿 } {人口 p12}] [{Made x} {in x} {中国 } {！ x}]]", words)
+	words, err = ReadWordPos(strings.NewReader("[中国/ns 人民/n]nt 银行/n  \n\n 1/2/m"))
+	expect(t, "<nil>", err)
+	expect(t, "[[{中国 ns} {人民 n} {银行 n}] [{1/2 m}]]", words)
+
+	buf.Reset()
+	for i, sentence := range sentences {
+		expect(t, "<nil>", WriteBMES(&buf, texts[i], sentence))
+	}
+	expect(t, "中\tB\n国\tE\n有\tS\n十\tB\n三\tM\n亿\tE\n人\tB\n口\tE\n\n"+
+		"M\tB\na\tM\nd\tM\ne\tE\ni\tB\nn\tE\n中\tB\n国\tE\n！\tS\n\n", buf.String())
+	words, err = ReadBMES(&buf)
+	expect(t, "<nil>", err)
+	expect(t, "[[{中国 } {有 } {十三亿 } {人口 }] [{Made } {in } {中国 } {！ }]]", words)
+	words, err = ReadBMES(strings.NewReader("中 B-ns\n国 E-ns\n人 B\n有 S-v\n"))
+	expect(t, "<nil>", err)
+	expect(t, "[[{中国 ns} {人 } {有 v}]]", words)
+	_, err = ReadBMES(strings.NewReader("中\n"))
+	expect(t, `sego: invalid BMES line "中"`, err)
+
+	buf.Reset()
+	for i, sentence := range sentences {
+		expect(t, "<nil>", WriteCoNLLU(&buf, texts[i], sentence))
+	}
+	expect(t, "# text = 中国有十三亿人口\n"+
+		"1\t中国\t_\t_\t_\t_\t_\t_\t_\tSpaceAfter=No\n"+
+		"2\t有\t_\t_\tp3\t_\t_\t_\t_\tSpaceAfter=No\n"+
+		"3\t十三亿\t_\t_\t_\t_\t_\t_\t_\tSpaceAfter=No\n"+
+		"4\t人口\t_\t_\tp12\t_\t_\t_\t_\tSpaceAfter=No\n\n"+
+		"# text = Made in 中国！\n"+
+		"1\tMade\t_\t_\tx\t_\t_\t_\t_\t_\n"+
+		"2\tin\t_\t_\tx\t_\t_\t_\t_\t_\n"+
+		"3\t中国\t_\t_\t_\t_\t_\t_\t_\tSpaceAfter=No\n"+
+		"4\t！\t_\t_\tx\t_\t_\t_\t_\tSpaceAfter=No\n\n", buf.String())
+	words, err = ReadCoNLLU(&buf)
+	expect(t, "<nil>", err)
+	expect(t, "[[{中国 } {有 p3} {十三亿 } {人口 p12}] [{Made x} {in x} {中国 } {！ x}]]", words)
+	words, err = ReadCoNLLU(strings.NewReader("# sent_id = 1\n1-2\t中国\t_\t_\t_\t_\t_\t_\t_\t_\n" +
+		"1\t中\t_\tPROPN\t_\t_\t_\t_\t_\t_\n1.1\t国\t_\t_\t_\t_\t_\t_\t_\t_\n2\t国\t_\t_\t_\t_\t_\t_\t_\t_"))
+	expect(t, "<nil>", err)
+	expect(t, "[[{中 PROPN} {国 }]]", words)
+	_, err = ReadCoNLLU(strings.NewReader("1\t中国\n"))
+	expect(t, fmt.Sprintf("sego: invalid CoNLL-U line %q", "1\t中国"), err)
+}
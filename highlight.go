@@ -0,0 +1,185 @@
+package sego
+
+import (
+	"html"
+	"sort"
+	"strings"
+)
+
+// 在文档中标出查询词并截取摘要，用于显示搜索结果
+//
+// 查询和文档用同一个分词器分词，并按搜索模式展开子分词，因此查询"中华人民共和国"
+// 可以匹配文档中的"人民共和国"，查询"共和国"也可以匹配文档中的"中华人民共和国"
+// 的子分词。英文按小写匹配。
+//
+//	highlighter := sego.NewHighlighter(&segmenter)
+//	snippet := highlighter.Highlight(query, document)
+type Highlighter struct {
+	seg *Segmenter
+
+	// 摘要包含的最多字符数（按Unicode码点计数），不大于零时输出整个文档
+	SnippetLength int
+
+	// 包围查询词的HTML标签
+	PreTag, PostTag string
+
+	// 摘要之前或之后有被截去的文本时添加的省略号
+	Ellipsis string
+
+	// 查询和文档展开子分词的选项
+	QueryOptions, DocumentOptions SearchOptions
+}
+
+// 创建使用seg分词的Highlighter，摘要长度为100个字符，查询词用<em>标签标出
+func NewHighlighter(seg *Segmenter) *Highlighter {
+	return &Highlighter{
+		seg:           seg,
+		SnippetLength: 100,
+		PreTag:        "<em>",
+		PostTag:       "</em>",
+		Ellipsis:      "…",
+		QueryOptions:  SearchOptions{Dedup: true},
+	}
+}
+
+// 返回文档中和查询词匹配的字节区间，区间按位置排序且互不重叠，重叠或相邻的
+// 匹配被合并
+func (h *Highlighter) Matches(query, document []byte) []Span {
+	terms := map[string]bool{}
+	for _, segment := range SearchSegments(h.seg.Segment(query), h.QueryOptions) {
+		if segment.token.IsTerm() {
+			terms[segment.token.Text()] = true
+		}
+	}
+
+	spans := []Span{}
+	if len(terms) == 0 {
+		return spans
+	}
+	for _, segment := range SearchSegments(h.seg.Segment(document), h.DocumentOptions) {
+		if terms[segment.token.Text()] {
+			spans = append(spans, Span{segment.start, segment.end})
+		}
+	}
+	return mergeSpans(spans)
+}
+
+// 截取包含最多查询词的摘要，转义其中的HTML特殊字符，并用PreTag和PostTag标出
+// 查询词
+func (h *Highlighter) Highlight(query, document []byte) string {
+	return h.HighlightFunc(query, document, func(text string, matched bool) string {
+		if matched {
+			return h.PreTag + html.EscapeString(text) + h.PostTag
+		}
+		return html.EscapeString(text)
+	})
+}
+
+// 截取包含最多查询词的摘要，摘要按是否为查询词分成若干段，每段的输出由render
+// 决定，render负责转义
+func (h *Highlighter) HighlightFunc(
+	query, document []byte, render func(text string, matched bool) string) string {
+	matches := h.Matches(query, document)
+	start, end := h.snippetWindow(document, matches)
+
+	var output strings.Builder
+	if start > 0 {
+		output.WriteString(h.Ellipsis)
+	}
+	position := start
+	for _, match := range matches {
+		if match.End <= start || match.Start >= end {
+			continue
+		}
+		if match.Start > position {
+			output.WriteString(render(string(document[position:match.Start]), false))
+		}
+		output.WriteString(render(string(document[match.Start:match.End]), true))
+		position = match.End
+	}
+	if position < end {
+		output.WriteString(render(string(document[position:end]), false))
+	}
+	if end < len(document) {
+		output.WriteString(h.Ellipsis)
+	}
+	return output.String()
+}
+
+// 选择摘要的字节区间：在SnippetLength个字符的窗口中包含的查询词最多，窗口从
+// 第一个查询词之前留出少量上下文开始。摘要不会切开查询词，查询词比SnippetLength
+// 长时摘要包含整个查询词。
+func (h *Highlighter) snippetWindow(document []byte, matches []Span) (start, end int) {
+	if h.SnippetLength <= 0 {
+		return 0, len(document)
+	}
+	converter := NewOffsetConverter(document)
+	length := converter.Convert(len(document), ByteOffset, RuneOffset)
+	if length <= h.SnippetLength {
+		return 0, len(document)
+	}
+
+	// 以每个查询词开始的窗口中包含的完整查询词的数目，取最多的一个
+	best, bestCount := -1, 0
+	for i := range matches {
+		windowEnd := converter.Convert(matches[i].Start, ByteOffset, RuneOffset) + h.SnippetLength
+		count := 0
+		for j := i; j < len(matches) &&
+			converter.Convert(matches[j].End, ByteOffset, RuneOffset) <= windowEnd; j++ {
+			count++
+		}
+		if count > bestCount {
+			best, bestCount = i, count
+		}
+	}
+	// 所有查询词都比摘要长时选择第一个查询词
+	if best == -1 && len(matches) > 0 {
+		best = 0
+	}
+
+	start = 0
+	if best != -1 {
+		// 在查询词之前留出最多四分之一窗口的上下文，但不超过前一个查询词
+		start = converter.Convert(matches[best].Start, ByteOffset, RuneOffset)
+		start = maxInt(start-h.SnippetLength/4, 0)
+		if best > 0 {
+			start = maxInt(start, converter.Convert(matches[best-1].End, ByteOffset, RuneOffset))
+		}
+		start = minInt(start, length-h.SnippetLength)
+	}
+	end = start + h.SnippetLength
+	start = converter.Convert(start, RuneOffset, ByteOffset)
+	end = converter.Convert(end, RuneOffset, ByteOffset)
+
+	// 不切开查询词。选中的查询词比摘要长时扩大摘要以包含整个查询词，其它
+	// 查询词被切开时缩小摘要。
+	for i, match := range matches {
+		if match.Start < start && start < match.End {
+			start = match.End
+		}
+		if match.Start < end && end < match.End {
+			if i == best {
+				end = match.End
+			} else {
+				end = match.Start
+			}
+		}
+	}
+	return start, end
+}
+
+// 将区间按起始位置排序，并合并重叠或相邻的区间
+func mergeSpans(spans []Span) []Span {
+	sort.Slice(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+	output := spans[:0]
+	for _, span := range spans {
+		if n := len(output); n > 0 && span.Start <= output[n-1].End {
+			if span.End > output[n-1].End {
+				output[n-1].End = span.End
+			}
+			continue
+		}
+		output = append(output, span)
+	}
+	return output
+}
@@ -0,0 +1,46 @@
+package sego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHighlight(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict4.txt")
+	highlighter := NewHighlighter(&seg)
+
+	document := []byte("<p>中华人民共和国</p>成立于1949年，人民共和国")
+	expect(t, "[{9 24} {47 62}]", highlighter.Matches([]byte("人民共和国"), document))
+	expect(t, "&lt;p&gt;中华<em>人民共和国</em>&lt;/p&gt;成立于1949年，<em>人民共和国</em>",
+		highlighter.Highlight([]byte("人民共和国"), document))
+	// 查询的子分词也参与匹配
+	expect(t, "&lt;p&gt;<em>中华</em>人民共和国&lt;/p&gt;成立于1949年，人民共和国",
+		highlighter.Highlight([]byte("中华，"), document))
+	expect(t, "&lt;p&gt;中华人民共和国&lt;/p&gt;成立于1949年，人民共和国",
+		highlighter.Highlight([]byte("，。"), document))
+
+	render := func(text string, matched bool) string {
+		if matched {
+			return "[" + text + "]"
+		}
+		return text
+	}
+	expect(t, "<p>中华人民[共和国]</p>成立于1949年，人民[共和国]",
+		highlighter.HighlightFunc([]byte("共和国"), document, render))
+
+	// 截取包含最多查询词的摘要
+	document = []byte(strings.Repeat("的", 30) + "中华" + strings.Repeat("的", 30) + "共和国和共和国" +
+		strings.Repeat("的", 30))
+	highlighter.SnippetLength = 20
+	expect(t, "…的的的的的[共和国]和[共和国]的的的的的的的的…",
+		highlighter.HighlightFunc([]byte("中华共和国"), document, render))
+	expect(t, strings.Repeat("的", 20)+"…",
+		highlighter.HighlightFunc([]byte("没有"), document, render))
+
+	// 查询词比摘要长时摘要包含整个查询词
+	document = []byte(strings.Repeat("的", 10) + "中华人民共和国" + strings.Repeat("的", 10))
+	highlighter.SnippetLength = 3
+	expect(t, "…[中华人民共和国]…",
+		highlighter.HighlightFunc([]byte("中华人民共和国"), document, render))
+}
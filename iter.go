@@ -0,0 +1,18 @@
+//go:build go1.23
+
+package sego
+
+import (
+	"iter"
+)
+
+// 返回文本分词结果的迭代器，和Each一样使用缓冲池中的内存
+//
+//	for segment := range segmenter.All(text) {
+//		...
+//	}
+func (seg *Segmenter) All(bytes []byte) iter.Seq[Segment] {
+	return func(yield func(Segment) bool) {
+		seg.Each(bytes, yield)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build go1.23
+
+package sego
+
+import (
+	"testing"
+)
+
+func TestAll(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	output := []Segment{}
+	for segment := range seg.All([]byte("中国有十三亿人口")) {
+		output = append(output, segment)
+		if len(output) == 3 {
+			break
+		}
+	}
+	expect(t, "中国/ 有/p3 十三亿/ ", SegmentsToString(output, false))
+}
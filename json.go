@@ -0,0 +1,110 @@
+package sego
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// 分词的JSON格式，字段的顺序即输出的顺序
+type jsonSegment struct {
+	Text      string        `json:"text"`
+	Pos       string        `json:"pos"`
+	Start     int           `json:"start"`
+	End       int           `json:"end"`
+	Frequency int           `json:"frequency"`
+	Segments  []jsonSegment `json:"segments,omitempty"`
+}
+
+// 将分词转换为JSON格式，位置的单位为unit，subSegments为真时包括子分词树
+func toJSONSegment(s *Segment, unit OffsetUnit, subSegments bool) jsonSegment {
+	output := jsonSegment{}
+	output.Start, output.End = s.Offsets(unit)
+	if s.token == nil {
+		return output
+	}
+	output.Text = s.token.Text()
+	output.Pos = s.token.pos
+	output.Frequency = s.token.frequency
+	if subSegments {
+		for _, segment := range s.token.segments {
+			output.Segments = append(output.Segments, toJSONSegment(segment, unit, true))
+		}
+	}
+	return output
+}
+
+// 将分词输出为JSON格式：
+//
+//	{"text":"中国","pos":"ns","start":0,"end":6,"frequency":34488}
+//
+// start和end为字节位置。需要其它单位的位置或者输出子分词时使用JSONSegments。
+func (s Segment) MarshalJSON() ([]byte, error) {
+	return json.Marshal(toJSONSegment(&s, ByteOffset, false))
+}
+
+// 一段文本的分词结果的JSON格式，即NDJSONWriter输出的一行，也是分词服务器/json
+// 接口的输出格式：
+//
+//	{"segments":[{"text":"中国",...},...]}
+type JSONSegments struct {
+	Segments []Segment
+
+	// 分词的start和end的单位，默认为字节
+	Unit OffsetUnit
+
+	// 是否输出子分词，见NDJSONWriter.SubSegments
+	SubSegments bool
+}
+
+func (s JSONSegments) MarshalJSON() ([]byte, error) {
+	line := jsonSegments{Segments: make([]jsonSegment, len(s.Segments))}
+	for i := range s.Segments {
+		line.Segments[i] = toJSONSegment(&s.Segments[i], s.Unit, s.SubSegments)
+	}
+
+	// 不转义HTML字符，由外层的Encoder决定是否转义
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(&line); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// 以NDJSON格式（每行一个JSON对象）输出多段文本的分词结果，每段文本输出一行：
+//
+//	{"segments":[{"text":"中国",...},...]}
+//
+// 这和分词服务器/json接口的输出格式相同。
+type NDJSONWriter struct {
+	// 是否输出子分词（见Token.Segments），子分词输出在分词的segments字段中，
+	// 其位置为在该分词中的位置：
+	//
+	//	{"text":"人口","pos":"n",...,"segments":[{"text":"人",...},{"text":"口",...}]}
+	SubSegments bool
+
+	// 分词的start和end的单位，默认为字节
+	Unit OffsetUnit
+
+	encoder *json.Encoder
+}
+
+// 一行NDJSON的内容
+type jsonSegments struct {
+	Segments []jsonSegment `json:"segments"`
+}
+
+// 创建向w输出的NDJSONWriter
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return &NDJSONWriter{encoder: encoder}
+}
+
+// 输出一段文本的分词结果
+func (writer *NDJSONWriter) Write(segments []Segment) error {
+	return writer.encoder.Encode(JSONSegments{
+		Segments: segments, Unit: writer.Unit, SubSegments: writer.SubSegments})
+}
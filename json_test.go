@@ -0,0 +1,44 @@
+package sego
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestSegmentJSON(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	output, err := json.Marshal(seg.Segment([]byte("中国有<十三亿>人口")))
+	expect(t, "<nil>", err)
+	expect(t, `[{"text":"中国","pos":"","start":0,"end":6,"frequency":32},`+
+		`{"text":"有","pos":"p3","start":6,"end":9,"frequency":64},`+
+		`{"text":"\u003c","pos":"x","start":9,"end":10,"frequency":1},`+
+		`{"text":"十三亿","pos":"","start":10,"end":19,"frequency":4},`+
+		`{"text":"\u003e","pos":"x","start":19,"end":20,"frequency":1},`+
+		`{"text":"人口","pos":"p12","start":20,"end":26,"frequency":16}]`, string(output))
+
+	var buf bytes.Buffer
+	writer := NewNDJSONWriter(&buf)
+	expect(t, "<nil>", writer.Write(seg.Segment([]byte("<人口>"))))
+	expect(t, "<nil>", writer.Write(nil))
+	writer.SubSegments = true
+	expect(t, "<nil>", writer.Write(seg.Segment([]byte("十三亿"))))
+	expect(t, `{"segments":[{"text":"<","pos":"x","start":0,"end":1,"frequency":1},`+
+		`{"text":"人口","pos":"p12","start":1,"end":7,"frequency":16},`+
+		`{"text":">","pos":"x","start":7,"end":8,"frequency":1}]}`+"\n"+
+		`{"segments":[]}`+"\n"+
+		`{"segments":[{"text":"十三亿","pos":"","start":0,"end":9,"frequency":4,"segments":[`+
+		`{"text":"十三","pos":"p10","start":0,"end":6,"frequency":16,"segments":[`+
+		`{"text":"十","pos":"x","start":0,"end":3,"frequency":1},`+
+		`{"text":"三","pos":"","start":3,"end":6,"frequency":64}]},`+
+		`{"text":"亿","pos":"p5","start":6,"end":9,"frequency":64}]}]}`+"\n", buf.String())
+
+	// 按字符计算位置，json.Marshal转义HTML字符
+	output, err = json.Marshal(JSONSegments{Segments: seg.Segment([]byte("<人口>")), Unit: RuneOffset})
+	expect(t, "<nil>", err)
+	expect(t, `{"segments":[{"text":"\u003c","pos":"x","start":0,"end":1,"frequency":1},`+
+		`{"text":"人口","pos":"p12","start":1,"end":3,"frequency":16},`+
+		`{"text":"\u003e","pos":"x","start":3,"end":4,"frequency":1}]}`, string(output))
+}
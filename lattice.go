@@ -0,0 +1,236 @@
+package sego
+
+// 该结构体用于记录Viterbi算法中的一个向前分词跳转，即以某字元结尾的一个候选分词
+type jumper struct {
+	// 跳转对应的分词
+	token *Token
+
+	// 从文本段开始到该跳转（包括该分词）的最短路径值
+	minDistance float32
+
+	// 分词的第一个字元
+	start int32
+
+	// 最短路径上前一个跳转的下标，-1表示该分词位于文本段开头
+	prev int32
+
+	// 结尾字元相同的下一个跳转的下标，-1表示没有
+	next int32
+}
+
+// 分词网格，记录了一段文本候选分词对应的向前跳转
+//
+// 分词网格有两种形式：
+//	1. 没有Scorer时使用一元模型，路径长度和前一个分词无关，添加跳转只需考虑
+//	   前一个字元处的最短路径。此时jumpers[i]即为以第i个字元结尾的最短路径
+//	   上的跳转，其余跳转直接丢弃。
+//	2. 有Scorer时路径长度和前一个分词有关，需要考虑以前一个字元结尾的所有
+//	   跳转。此时jumpers按分词第一个字元的顺序记录所有跳转。
+type lattice struct {
+	// 文本段的字元
+	text []Text
+
+	// 计算路径长度的Scorer，为nil时使用分词的一元模型路径长度
+	scorer Scorer
+
+	// 是否记录所有跳转，即上面的第二种形式。keepAll为真时即使没有Scorer也记录
+	// 所有跳转，用于输出完整的分词网格。
+	full    bool
+	keepAll bool
+
+	// 跳转，见lattice结构体的注释
+	jumpers []jumper
+
+	// 记录所有跳转时，heads[i]和tails[i]为以第i个字元结尾的第一个和最后一个
+	// 跳转，同一字元结尾的跳转通过next串成链表；best[i]为其中路径值最小的一个
+	heads, tails, best []int32
+
+	// 查找词典时存放分词的缓冲区
+	tokens []*Token
+
+	// reuseTokens为真时伪分词存放在unknownTokens中重复使用，否则为每个伪分词
+	// 单独分配内存
+	reuseTokens   bool
+	unknownTokens []Token
+}
+
+// 为文本段text初始化分词网格，尽量重复使用上次分词时分配的内存
+func (l *lattice) reset(text []Text, scorer Scorer, maxTokenLength int) {
+	l.text = text
+	l.scorer = scorer
+	l.full = scorer != nil || l.keepAll
+	if l.full {
+		if cap(l.jumpers) < len(text) {
+			l.jumpers = make([]jumper, 0, len(text)*2)
+		}
+		l.jumpers = l.jumpers[:0]
+		l.heads = resizeInt32s(l.heads, len(text))
+		l.tails = resizeInt32s(l.tails, len(text))
+		l.best = resizeInt32s(l.best, len(text))
+		for i := range text {
+			l.heads[i] = -1
+			l.tails[i] = -1
+			l.best[i] = -1
+		}
+	} else {
+		if cap(l.jumpers) < len(text) {
+			l.jumpers = make([]jumper, len(text))
+		} else {
+			l.jumpers = l.jumpers[:len(text)]
+			for i := range l.jumpers {
+				l.jumpers[i] = jumper{}
+			}
+		}
+	}
+
+	if cap(l.tokens) < maxTokenLength {
+		l.tokens = make([]*Token, maxTokenLength)
+	}
+	l.tokens = l.tokens[:maxTokenLength]
+
+	// 每个字元最多一个伪分词，预先分配足够的容量保证伪分词的地址不变
+	if l.reuseTokens && cap(l.unknownTokens) < len(text) {
+		l.unknownTokens = make([]Token, 0, len(text))
+	}
+	l.unknownTokens = l.unknownTokens[:0]
+}
+
+// 返回第i个字元对应的伪分词
+func (l *lattice) unknownToken(i int) *Token {
+	if !l.reuseTokens {
+		return &Token{text: []Text{l.text[i]}, frequency: 1, distance: UnknownTokenDistance, pos: "x"}
+	}
+	l.unknownTokens = append(l.unknownTokens, Token{
+		text: l.text[i : i+1], frequency: 1, distance: UnknownTokenDistance, pos: "x"})
+	return &l.unknownTokens[len(l.unknownTokens)-1]
+}
+
+// 返回以第i个字元结尾的最短路径上的跳转下标，该字元不可到达时返回-1
+func (l *lattice) bestAt(i int) int32 {
+	if l.full {
+		return l.best[i]
+	}
+	if l.jumpers[i].token == nil {
+		return -1
+	}
+	return int32(i)
+}
+
+// 添加一个从字元start到字元end的分词跳转，并更新end处的最短路径:
+//	1. 当end处从未被访问过时，或者
+//	2. 当end处的当前最短路径大于新的最短路径时
+// 将end处的最短路径更新为新跳转。
+func (l *lattice) addJumper(start, end int, token *Token) {
+	if !l.full {
+		newJumper := jumper{token: token, start: int32(start), prev: -1}
+		if start == 0 {
+			// 当分词在文本首部时，基础距离应该是零
+			newJumper.minDistance = token.distance
+		} else {
+			newJumper.prev = int32(start - 1)
+			newJumper.minDistance = l.jumpers[start-1].minDistance + token.distance
+		}
+		if l.jumpers[end].token == nil || l.jumpers[end].minDistance > newJumper.minDistance {
+			l.jumpers[end] = newJumper
+		}
+		return
+	}
+
+	newJumper := jumper{token: token, start: int32(start), prev: -1, next: -1}
+	if start == 0 {
+		newJumper.minDistance = l.distance(start, nil, token)
+	} else {
+		for index := l.heads[start-1]; index != -1; index = l.jumpers[index].next {
+			distance := l.jumpers[index].minDistance +
+				l.distance(start, l.jumpers[index].token, token)
+			if newJumper.prev == -1 || distance < newJumper.minDistance {
+				newJumper.minDistance = distance
+				newJumper.prev = index
+			}
+		}
+	}
+
+	// 将新跳转加入end处的链表尾部
+	index := int32(len(l.jumpers))
+	if l.heads[end] == -1 {
+		l.heads[end] = index
+	} else {
+		l.jumpers[l.tails[end]].next = index
+	}
+	l.tails[end] = index
+	l.jumpers = append(l.jumpers, newJumper)
+
+	if l.best[end] == -1 || l.jumpers[l.best[end]].minDistance > newJumper.minDistance {
+		l.best[end] = index
+	}
+}
+
+// 返回从字元start开始的分词token在前一个分词为prev时的路径长度
+func (l *lattice) distance(start int, prev, token *Token) float32 {
+	if l.scorer == nil {
+		return token.distance
+	}
+	return l.scorer.Score(l.text, start, prev, token)
+}
+
+// 返回最短路径上的分词数目
+func (l *lattice) bestPathLength() int {
+	if len(l.text) == 0 {
+		return 0
+	}
+	numSeg := 0
+	for index := l.bestAt(len(l.text) - 1); index != -1; index = l.jumpers[index].prev {
+		numSeg++
+	}
+	return numSeg
+}
+
+// 将最短路径上的分词按顺序添加到output之后
+func (l *lattice) appendBestPath(output []Segment) []Segment {
+	if len(l.text) == 0 {
+		return output
+	}
+
+	// 从后向前扫描第一遍得到需要添加的分词数目
+	numSeg := l.bestPathLength()
+
+	// 从后向前扫描第二遍添加分词到最终结果
+	first := len(output)
+	output = growSegments(output, numSeg)
+	iSeg := len(output)
+	for index := l.bestAt(len(l.text) - 1); index != -1; index = l.jumpers[index].prev {
+		iSeg--
+		output[iSeg].token = l.jumpers[index].token
+	}
+
+	// 计算各个分词的字节位置、字符位置和UTF-16位置
+	var position offset
+	for iSeg := first; iSeg < len(output); iSeg++ {
+		output[iSeg].start = position.bytes
+		output[iSeg].runeStart = position.runes
+		output[iSeg].utf16Start = position.utf16
+		position.addText(output[iSeg].token.text)
+		output[iSeg].end = position.bytes
+		output[iSeg].runeEnd = position.runes
+		output[iSeg].utf16End = position.utf16
+	}
+	return output
+}
+
+// 将数组的长度调整为n，容量不够时重新分配
+func resizeInt32s(a []int32, n int) []int32 {
+	if cap(a) < n {
+		return make([]int32, n)
+	}
+	return a[:n]
+}
+
+// 将分词数组的长度增加n，容量不够时重新分配
+func growSegments(segments []Segment, n int) []Segment {
+	if cap(segments)-len(segments) < n {
+		newSegments := make([]Segment, len(segments), len(segments)+n)
+		copy(newSegments, segments)
+		segments = newSegments
+	}
+	return segments[:len(segments)+n]
+}
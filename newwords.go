@@ -0,0 +1,257 @@
+package sego
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// 新词发现的参数，默认值见DefaultNewWordOptions
+type NewWordOptions struct {
+	// 候选词最多包含的字元数，不大于零时为4
+	MaxWordLength int
+
+	// 候选词在语料中的最小出现次数，不大于零时为5
+	MinFrequency int
+
+	// 候选词的内部凝固度下限，默认为3。凝固度定义为将候选词切成两部分的所有
+	// 方式中点互信息log2(p(词)/(p(左部分)*p(右部分)))的最小值。凝固度可以为
+	// 负数，因此零值表示下限为零而不是使用默认值。
+	MinPMI float64
+
+	// 候选词左右邻字信息熵（以2为底）中较小值的下限，默认为1，零值表示不限制
+	MinEntropy float64
+}
+
+// 一个新词候选
+type NewWord struct {
+	// 候选词文本
+	Text string
+
+	// 候选词在语料中的出现次数
+	Frequency int
+
+	// 内部凝固度，见NewWordOptions.MinPMI的注释
+	PMI float64
+
+	// 左邻字信息熵
+	LeftEntropy float64
+
+	// 右邻字信息熵
+	RightEntropy float64
+}
+
+// 新词发现器
+//
+// 用法：反复调用Add或AddReader扫描语料，最后调用Find得到不在词典中的新词候选。
+// 统计量保存在内存中，所需内存和语料中不同n元组的数目成正比。
+type NewWordFinder struct {
+	options    NewWordOptions
+	ngrams     map[string]*ngramStats
+	totalWords int64
+}
+
+// n元组的统计信息
+type ngramStats struct {
+	// 出现次数
+	frequency int
+
+	// 各字元在n元组中的结束字节位置，用于切分n元组计算凝固度
+	bounds []int
+
+	// 左右邻字的出现次数，仅对二元及以上的n元组统计
+	left, right map[string]int
+
+	// 左右两侧是文本边界（标点、空白等）的次数，每次视为一个不同的邻字
+	leftBoundary, rightBoundary int
+}
+
+// 返回新词发现的默认参数
+func DefaultNewWordOptions() NewWordOptions {
+	return NewWordOptions{MaxWordLength: 4, MinFrequency: 5, MinPMI: 3, MinEntropy: 1}
+}
+
+// 创建新词发现器
+func NewNewWordFinder(options NewWordOptions) *NewWordFinder {
+	defaults := DefaultNewWordOptions()
+	if options.MaxWordLength <= 0 {
+		options.MaxWordLength = defaults.MaxWordLength
+	}
+	if options.MinFrequency <= 0 {
+		options.MinFrequency = defaults.MinFrequency
+	}
+	return &NewWordFinder{
+		options: options,
+		ngrams:  make(map[string]*ngramStats),
+	}
+}
+
+// 扫描一段语料
+//
+// 文本在标点、空白等非文字字元处断开，候选词不会跨越这些字元。
+func (finder *NewWordFinder) Add(text []byte) {
+	words := splitTextToWords(text)
+	start := 0
+	for current := 0; current <= len(words); current++ {
+		if current == len(words) || !isWordCharacter(words[current]) {
+			finder.addRun(words[start:current])
+			start = current + 1
+		}
+	}
+}
+
+// 逐行扫描reader中的语料直到读完
+func (finder *NewWordFinder) AddReader(reader io.Reader) error {
+	bufReader := bufio.NewReader(reader)
+	for {
+		line, err := bufReader.ReadBytes('\n')
+		if len(line) > 0 {
+			finder.Add(line)
+		}
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// 统计一段不含非文字字元的字元序列中的所有n元组
+func (finder *NewWordFinder) addRun(words []Text) {
+	finder.totalWords += int64(len(words))
+	for start := 0; start < len(words); start++ {
+		end := minInt(start+finder.options.MaxWordLength, len(words))
+		for last := start; last < end; last++ {
+			stats := finder.ngram(words[start : last+1])
+			stats.frequency++
+			if last == start {
+				continue
+			}
+
+			// 统计左右邻字
+			if start == 0 {
+				stats.leftBoundary++
+			} else {
+				stats.left[string(words[start-1])]++
+			}
+			if last == len(words)-1 {
+				stats.rightBoundary++
+			} else {
+				stats.right[string(words[last+1])]++
+			}
+		}
+	}
+}
+
+// 返回字元组words对应的n元组统计信息，不存在时新建
+func (finder *NewWordFinder) ngram(words []Text) *ngramStats {
+	key := textSliceToString(words)
+	if stats, ok := finder.ngrams[key]; ok {
+		return stats
+	}
+
+	stats := &ngramStats{bounds: make([]int, len(words))}
+	length := 0
+	for i, word := range words {
+		length += len(word)
+		stats.bounds[i] = length
+	}
+	if len(words) > 1 {
+		stats.left = make(map[string]int)
+		stats.right = make(map[string]int)
+	}
+	finder.ngrams[key] = stats
+	return stats
+}
+
+// 返回满足条件且不在词典dict中的新词候选，按出现次数从高到低排列
+//
+// dict为nil时不过滤任何候选。
+func (finder *NewWordFinder) Find(dict *Dictionary) []NewWord {
+	output := []NewWord{}
+	for text, stats := range finder.ngrams {
+		if len(stats.bounds) < 2 || stats.frequency < finder.options.MinFrequency {
+			continue
+		}
+		if dict != nil && dict.Lookup([]byte(text)) != nil {
+			continue
+		}
+
+		pmi := finder.pmi(text, stats)
+		if pmi < finder.options.MinPMI {
+			continue
+		}
+		leftEntropy := neighborEntropy(stats.left, stats.leftBoundary, stats.frequency)
+		rightEntropy := neighborEntropy(stats.right, stats.rightBoundary, stats.frequency)
+		if math.Min(leftEntropy, rightEntropy) < finder.options.MinEntropy {
+			continue
+		}
+
+		output = append(output, NewWord{
+			Text:         text,
+			Frequency:    stats.frequency,
+			PMI:          pmi,
+			LeftEntropy:  leftEntropy,
+			RightEntropy: rightEntropy,
+		})
+	}
+
+	sort.Slice(output, func(i, j int) bool {
+		if output[i].Frequency != output[j].Frequency {
+			return output[i].Frequency > output[j].Frequency
+		}
+		return output[i].Text < output[j].Text
+	})
+	return output
+}
+
+// 计算n元组的内部凝固度
+func (finder *NewWordFinder) pmi(text string, stats *ngramStats) float64 {
+	total := float64(finder.totalWords)
+	minPMI := math.Inf(1)
+	for _, bound := range stats.bounds[:len(stats.bounds)-1] {
+		left := finder.ngrams[text[:bound]]
+		right := finder.ngrams[text[bound:]]
+		pmi := math.Log2(float64(stats.frequency) * total /
+			(float64(left.frequency) * float64(right.frequency)))
+		if pmi < minPMI {
+			minPMI = pmi
+		}
+	}
+	return minPMI
+}
+
+// 计算邻字的信息熵，其中numBoundaries次文本边界各自视为一个不同的邻字
+func neighborEntropy(neighbors map[string]int, numBoundaries int, total int) float64 {
+	entropy := 0.0
+	for _, count := range neighbors {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	if numBoundaries > 0 {
+		p := 1 / float64(total)
+		entropy -= float64(numBoundaries) * p * math.Log2(p)
+	}
+	return entropy
+}
+
+// 将新词候选以词典格式（每行"分词文本 频率 词性"）写入writer
+//
+// pos为空时省略词性一栏，LoadDictionary会将其词性设为空字符串。
+func WriteNewWords(writer io.Writer, words []NewWord, pos string) error {
+	bufWriter := bufio.NewWriter(writer)
+	for _, word := range words {
+		var err error
+		if pos == "" {
+			_, err = fmt.Fprintf(bufWriter, "%s %d\n", word.Text, word.Frequency)
+		} else {
+			_, err = fmt.Fprintf(bufWriter, "%s %d %s\n", word.Text, word.Frequency, pos)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return bufWriter.Flush()
+}
@@ -0,0 +1,45 @@
+package sego
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNewWordFinder(t *testing.T) {
+	corpus := strings.Join([]string{
+		"今天蓝瘦香菇了",
+		"我很蓝瘦香菇。",
+		"蓝瘦香菇是什么意思",
+		"他说蓝瘦香菇，大家都笑了",
+		"别再蓝瘦香菇啦",
+		"有人蓝瘦香菇吗",
+	}, "\n")
+
+	finder := NewNewWordFinder(NewWordOptions{MinFrequency: 6, MinPMI: 2, MinEntropy: 2})
+	expect(t, "<nil>", finder.AddReader(strings.NewReader(corpus)))
+
+	words := finder.Find(nil)
+	expect(t, "1", len(words))
+	expect(t, "蓝瘦香菇", words[0].Text)
+	expect(t, "6", words[0].Frequency)
+
+	var buf bytes.Buffer
+	expect(t, "<nil>", WriteNewWords(&buf, words, "nw"))
+	expect(t, "蓝瘦香菇 6 nw\n", buf.String())
+
+	// 已在词典中的词不输出
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict2.txt")
+	finder = NewNewWordFinder(NewWordOptions{MinFrequency: 2, MinPMI: 0.1, MinEntropy: 0.1})
+	finder.Add([]byte("中国，中国。中国！中国"))
+	expect(t, "0", len(finder.Find(seg.Dictionary())))
+	expect(t, "1", len(finder.Find(nil)))
+
+	// 凝固度和信息熵的下限为零时不使用默认值
+	finder = NewNewWordFinder(NewWordOptions{})
+	expect(t, "0", finder.options.MinPMI)
+	expect(t, "0", finder.options.MinEntropy)
+	expect(t, "4", finder.options.MaxWordLength)
+	expect(t, "5", finder.options.MinFrequency)
+}
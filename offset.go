@@ -0,0 +1,165 @@
+package sego
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// 文本中位置的计数单位
+type OffsetUnit int
+
+const (
+	// 按字节计数，即Go中[]byte和string的下标
+	ByteOffset OffsetUnit = iota
+
+	// 按Unicode码点计数，即Go中[]rune的下标，Python 3中str的下标
+	RuneOffset
+
+	// 按UTF-16编码单元计数，即JavaScript、Java中字符串的下标，Elasticsearch
+	// 返回的位置也按此计数
+	UTF16Offset
+)
+
+// 返回分词在指定计数单位下的起始和结束位置
+func (s *Segment) Offsets(unit OffsetUnit) (start, end int) {
+	switch unit {
+	case RuneOffset:
+		return s.runeStart, s.runeEnd
+	case UTF16Offset:
+		return s.utf16Start, s.utf16End
+	}
+	return s.start, s.end
+}
+
+// 返回各个分词在指定计数单位下的起始和结束位置
+func SegmentSpans(segs []Segment, unit OffsetUnit) []Span {
+	spans := make([]Span, len(segs))
+	for i := range segs {
+		spans[i].Start, spans[i].End = segs[i].Offsets(unit)
+	}
+	return spans
+}
+
+// 同时按三种计数单位记录的文本位置
+type offset struct {
+	bytes, runes, utf16 int
+}
+
+// 将分词的各种位置向后移动o，用于把一段文本的分词结果转换为在整个文本中的位置
+func shiftSegments(segments []Segment, o offset) {
+	for i := range segments {
+		segments[i].shift(o)
+	}
+}
+
+// 将分词的各种位置向后移动o
+func (s *Segment) shift(o offset) {
+	s.start += o.bytes
+	s.end += o.bytes
+	s.runeStart += o.runes
+	s.runeEnd += o.runes
+	s.utf16Start += o.utf16
+	s.utf16End += o.utf16
+}
+
+// 将位置向后移动一段字元
+func (o *offset) addText(text []Text) {
+	for _, word := range text {
+		o.add(word)
+	}
+}
+
+// 将位置向后移动一段文本，无效的UTF-8字节按一个字符计算，和[]rune转换的结果一致
+func (o *offset) add(bytes []byte) {
+	o.bytes += len(bytes)
+	for i := 0; i < len(bytes); {
+		if bytes[i] < utf8.RuneSelf {
+			// ASCII字符
+			i++
+			o.runes++
+			o.utf16++
+			continue
+		}
+		r, size := utf8.DecodeRune(bytes[i:])
+		i += size
+		o.runes++
+		o.utf16 += utf16Length(r)
+	}
+}
+
+// 返回字符r的UTF-16编码单元数
+func utf16Length(r rune) int {
+	if r >= 0x10000 && r <= utf8.MaxRune {
+		return 2
+	}
+	return 1
+}
+
+// 在同一段文本的三种计数单位之间转换位置，用于处理其它语言传来的位置
+type OffsetConverter struct {
+	// 每个字符的起始位置，最后一项为文本末尾
+	bytes []int
+	utf16 []int
+}
+
+// 为文本创建位置转换器
+func NewOffsetConverter(text []byte) *OffsetConverter {
+	numRunes := utf8.RuneCount(text)
+	converter := &OffsetConverter{
+		bytes: make([]int, 0, numRunes+1),
+		utf16: make([]int, 0, numRunes+1),
+	}
+	var position offset
+	for i := 0; i < len(text); {
+		converter.bytes = append(converter.bytes, position.bytes)
+		converter.utf16 = append(converter.utf16, position.utf16)
+		_, size := utf8.DecodeRune(text[i:])
+		position.add(text[i : i+size])
+		i += size
+	}
+	converter.bytes = append(converter.bytes, position.bytes)
+	converter.utf16 = append(converter.utf16, position.utf16)
+	return converter
+}
+
+// 将位置从计数单位from转换为计数单位to
+//
+// 位置落在一个字符内部时（比如UTF-8多字节字符的中间）转换为该字符的起始位置，
+// 超出文本范围的位置转换为文本开头或末尾。
+func (c *OffsetConverter) Convert(position int, from, to OffsetUnit) int {
+	r := c.runeIndex(position, from)
+	switch to {
+	case ByteOffset:
+		return c.bytes[r]
+	case UTF16Offset:
+		return c.utf16[r]
+	}
+	return r
+}
+
+// 将分词的位置从计数单位from转换为计数单位to
+func (c *OffsetConverter) ConvertSpans(spans []Span, from, to OffsetUnit) []Span {
+	output := make([]Span, len(spans))
+	for i, span := range spans {
+		output[i].Start = c.Convert(span.Start, from, to)
+		output[i].End = c.Convert(span.End, from, to)
+	}
+	return output
+}
+
+// 返回位置所在字符的下标
+func (c *OffsetConverter) runeIndex(position int, unit OffsetUnit) int {
+	last := len(c.bytes) - 1
+	var positions []int
+	switch unit {
+	case ByteOffset:
+		positions = c.bytes
+	case UTF16Offset:
+		positions = c.utf16
+	default:
+		return minInt(maxInt(position, 0), last)
+	}
+	// 找到第一个起始位置大于position的字符，其前一个字符即为所求
+	r := sort.SearchInts(positions, position+1) - 1
+	return minInt(maxInt(r, 0), last)
+}
@@ -0,0 +1,74 @@
+package sego
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// 检查各个分词的字符位置和UTF-16位置是否和字节位置一致
+func checkOffsets(t *testing.T, text []byte, segments []Segment) {
+	// 每个字节位置之前的字符数和UTF-16编码单元数
+	runes := make([]int, len(text)+1)
+	utf16s := make([]int, len(text)+1)
+	for position := 0; position < len(text); {
+		r, size := utf8.DecodeRune(text[position:])
+		for i := position + 1; i <= position+size; i++ {
+			runes[i] = runes[position] + 1
+			utf16s[i] = utf16s[position] + len(utf16.Encode([]rune{r}))
+		}
+		position += size
+	}
+
+	for _, segment := range segments {
+		if segment.RuneStart() != runes[segment.start] || segment.RuneEnd() != runes[segment.end] ||
+			segment.UTF16Start() != utf16s[segment.start] || segment.UTF16End() != utf16s[segment.end] {
+			t.Fatalf("分词%s位置错误 %d %d %d %d", segment.token.Text(),
+				segment.RuneStart(), segment.RuneEnd(), segment.UTF16Start(), segment.UTF16End())
+		}
+	}
+}
+
+func TestSegmentOffsets(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	text := []byte("中国😀有 Hello十三亿\xff人口𝄞")
+	segments := seg.Segment(text)
+	checkOffsets(t, text, segments)
+	expect(t, "[{0 2} {2 4} {4 5} {5 6} {6 11} {11 14} {14 15} {15 17} {17 19}]",
+		SegmentSpans(segments, UTF16Offset))
+	expect(t, "[{0 2} {2 3} {3 4} {4 5} {5 10} {10 13} {13 14} {14 16} {16 17}]",
+		SegmentSpans(segments, RuneOffset))
+
+	content, _ := ioutil.ReadFile("testdata/bailuyuan.txt")
+	content = content[:20000]
+	checkOffsets(t, content, seg.Segment(content))
+	checkOffsets(t, content, seg.SegmentInto(nil, content, new(Workspace)))
+
+	// 流式分词的位置为在整个流中的位置
+	scanner := NewScanner(&seg, bytes.NewReader(content))
+	scanner.chunkSize = 100
+	output := []Segment{}
+	for scanner.Scan() {
+		output = append(output, scanner.Segment())
+	}
+	checkOffsets(t, content, output)
+}
+
+func TestOffsetConverter(t *testing.T) {
+	converter := NewOffsetConverter([]byte("a中😀b"))
+	expect(t, "[{0 1} {1 4} {4 8} {8 9}]", converter.ConvertSpans(
+		[]Span{{0, 1}, {1, 2}, {2, 4}, {4, 5}}, UTF16Offset, ByteOffset))
+	expect(t, "[{0 1} {1 2} {2 3} {3 4}]", converter.ConvertSpans(
+		[]Span{{0, 1}, {1, 4}, {4, 8}, {8, 9}}, ByteOffset, RuneOffset))
+	expect(t, "5", converter.Convert(4, RuneOffset, UTF16Offset))
+
+	// 字符内部的位置转换为字符的起始位置，超出范围的位置转换为文本开头或末尾
+	expect(t, "1", converter.Convert(2, ByteOffset, RuneOffset))
+	expect(t, "2", converter.Convert(3, UTF16Offset, RuneOffset))
+	expect(t, "0", converter.Convert(-1, ByteOffset, UTF16Offset))
+	expect(t, "9", converter.Convert(100, UTF16Offset, ByteOffset))
+}
@@ -0,0 +1,7 @@
+//go:build race
+// +build race
+
+package sego
+
+// 是否开启了竞态检测，开启时sync.Pool会随机丢弃对象
+const raceEnabled = true
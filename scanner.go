@@ -0,0 +1,184 @@
+package sego
+
+import (
+	"io"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// 流式分词每次至少读入的字节数
+	defaultScannerChunkSize = 64 * 1024
+
+	// 流式分词缓冲区的最大字节数，超过时强制切开
+	defaultScannerMaxBufferSize = 1024 * 1024
+)
+
+// 流式分词器，从io.Reader中分块读入文本并逐个输出分词，适合对无法一次读入
+// 内存的大文件分词。用法和bufio.Scanner类似：
+//
+//	scanner := sego.NewScanner(&segmenter, reader)
+//	for scanner.Scan() {
+//		segment := scanner.Segment()
+//		...
+//	}
+//	if err := scanner.Err(); err != nil {
+//		...
+//	}
+//
+// 每读满一块文本，分词器在最后一个标点或空白字符之后切开并对前面的部分分词；
+// 整块文本中没有标点和空白时，对整块分词并保留最后至少MaxTokenLength个字元留待
+// 和后续文本一起分词，因此切开的位置不会落在词典中的分词内部。
+//
+// 很长的英文或数字串（比如没有空格的base64文本）是一个字元，无法保留足够的
+// 字元切开，此时缓冲区超过1MB后在末尾强制切开，因此内存占用有上限，但切开
+// 位置处的分词可能和一次对整个文本分词的结果不同。
+type Scanner struct {
+	seg    *Segmenter
+	reader io.Reader
+
+	// 每次至少读入的字节数，以及缓冲区的最大字节数
+	chunkSize     int
+	maxBufferSize int
+
+	// 已读入但尚未分词的文本，以及其开头在整个流中的位置
+	buffer []byte
+	offset offset
+
+	// 已分词但尚未输出的分词，以及当前输出的分词
+	segments []Segment
+	segment  Segment
+
+	eof bool
+	err error
+}
+
+// 创建从reader中读入文本的流式分词器
+func NewScanner(seg *Segmenter, reader io.Reader) *Scanner {
+	return &Scanner{
+		seg:           seg,
+		reader:        reader,
+		chunkSize:     defaultScannerChunkSize,
+		maxBufferSize: defaultScannerMaxBufferSize,
+	}
+}
+
+// 前进到下一个分词，没有更多分词或者读入出错时返回false
+func (scanner *Scanner) Scan() bool {
+	for len(scanner.segments) == 0 {
+		if scanner.err != nil {
+			return false
+		}
+		if scanner.eof {
+			if len(scanner.buffer) == 0 {
+				return false
+			}
+			scanner.segmentBuffer(len(scanner.buffer))
+			continue
+		}
+
+		scanner.read()
+		if len(scanner.buffer) < scanner.chunkSize {
+			continue
+		}
+		if cut := lastSafeCut(scanner.buffer); cut > 0 {
+			scanner.segmentBuffer(cut)
+		} else if !scanner.segmentBufferKeepingTail() && len(scanner.buffer) >= scanner.maxBufferSize {
+			// 无法保留足够的字元时强制切开，以免缓冲区无限增长
+			scanner.segmentBuffer(completeRunesLength(scanner.buffer))
+		}
+	}
+
+	scanner.segment = scanner.segments[0]
+	scanner.segments = scanner.segments[1:]
+	return true
+}
+
+// 返回当前分词，分词的各种位置均为在整个流中的位置
+func (scanner *Scanner) Segment() Segment {
+	return scanner.segment
+}
+
+// 返回读入时遇到的第一个错误，读到流末尾不视为错误
+func (scanner *Scanner) Err() error {
+	return scanner.err
+}
+
+// 从reader中读入一次
+func (scanner *Scanner) read() {
+	if cap(scanner.buffer)-len(scanner.buffer) < scanner.chunkSize {
+		buffer := make([]byte, len(scanner.buffer), len(scanner.buffer)+scanner.chunkSize)
+		copy(buffer, scanner.buffer)
+		scanner.buffer = buffer
+	}
+	n, err := scanner.reader.Read(scanner.buffer[len(scanner.buffer):cap(scanner.buffer)])
+	scanner.buffer = scanner.buffer[:len(scanner.buffer)+n]
+	if err == io.EOF {
+		scanner.eof = true
+	} else if err != nil {
+		scanner.err = err
+	}
+}
+
+// 对缓冲区中前cut个字节分词
+func (scanner *Scanner) segmentBuffer(cut int) {
+	segments := scanner.seg.Segment(scanner.buffer[:cut])
+	scanner.appendSegments(segments, cut)
+}
+
+// 对整个缓冲区分词，只输出最后至少MaxTokenLength个字元之前的分词，其余的留在
+// 缓冲区中。缓冲区中的字元不够多、无法输出分词时返回false。
+func (scanner *Scanner) segmentBufferKeepingTail() bool {
+	segments := scanner.seg.Segment(scanner.buffer[:completeRunesLength(scanner.buffer)])
+	numWords := 0
+	numSegments := len(segments)
+	for numSegments > 0 && numWords < scanner.seg.dict.maxTokenLength {
+		numSegments--
+		numWords += len(segments[numSegments].token.text)
+	}
+	if numSegments == 0 {
+		// 保留的字元不够时只能等待更多文本
+		return false
+	}
+	scanner.appendSegments(segments[:numSegments], segments[numSegments].start)
+	return true
+}
+
+// 去掉末尾不完整的UTF8字符后文本的字节数
+func completeRunesLength(text []byte) int {
+	end := len(text)
+	for i := 0; i < utf8.UTFMax && i < end; i++ {
+		if utf8.RuneStart(text[end-1-i]) {
+			if !utf8.FullRune(text[end-1-i : end]) {
+				end = end - 1 - i
+			}
+			break
+		}
+	}
+	return end
+}
+
+// 将分词的各种位置转换为在整个流中的位置后加入待输出分词，并从缓冲区中移除
+// 前cut个字节。已输出分词中的伪分词引用了缓冲区的内存，因此剩余文本需要拷贝
+// 到新的缓冲区中。
+func (scanner *Scanner) appendSegments(segments []Segment, cut int) {
+	shiftSegments(segments, scanner.offset)
+	scanner.segments = append(scanner.segments, segments...)
+	scanner.offset.add(scanner.buffer[:cut])
+
+	buffer := make([]byte, len(scanner.buffer)-cut, len(scanner.buffer)-cut+scanner.chunkSize)
+	copy(buffer, scanner.buffer[cut:])
+	scanner.buffer = buffer
+}
+
+// 返回文本中最后一个标点或空白字符之后的字节位置，没有时返回0
+func lastSafeCut(text []byte) int {
+	for end := len(text); end > 0; {
+		r, size := utf8.DecodeLastRune(text[:end])
+		if unicode.IsSpace(r) || unicode.IsPunct(r) {
+			return end
+		}
+		end -= size
+	}
+	return 0
+}
@@ -0,0 +1,81 @@
+package sego
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"testing/iotest"
+)
+
+func TestScanner(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	content, _ := ioutil.ReadFile("testdata/bailuyuan.txt")
+	content = content[:20000]
+	for _, chunkSize := range []int{7, 100, 4096} {
+		scanner := NewScanner(&seg, iotest.HalfReader(bytes.NewReader(content)))
+		scanner.chunkSize = chunkSize
+		output := []Segment{}
+		for scanner.Scan() {
+			output = append(output, scanner.Segment())
+		}
+		expect(t, "<nil>", scanner.Err())
+		expect(t, SegmentsToString(seg.Segment(content), false), SegmentsToString(output, false))
+		position := 0
+		for _, segment := range output {
+			if segment.start != position ||
+				segment.end-segment.start != textSliceByteLength(segment.token.text) {
+				t.Fatalf("分词位置错误 %d %d %s", segment.start, segment.end, segment.token.Text())
+			}
+			position = segment.end
+		}
+		expect(t, "20000", position)
+	}
+
+	// 没有标点和空白的文本在至少保留MaxTokenLength个字元后切开
+	text := bytes.Repeat([]byte("中国有十三亿人口"), 10)
+	scanner := NewScanner(&seg, bytes.NewReader(text))
+	scanner.chunkSize = 30
+	output := []Segment{}
+	for scanner.Scan() {
+		output = append(output, scanner.Segment())
+	}
+	expect(t, SegmentsToString(seg.Segment(text), false), SegmentsToString(output, false))
+	expect(t, "240", output[len(output)-1].end)
+
+	// 保留的字元不够MaxTokenLength个时不输出分词："十三亿人口"只能保留"人口"两个字元
+	scanner = NewScanner(&seg, nil)
+	scanner.buffer = []byte("十三亿人口")
+	expect(t, "false", scanner.segmentBufferKeepingTail())
+	expect(t, "0", len(scanner.segments))
+	scanner.buffer = []byte("中国有十三亿人口")
+	expect(t, "true", scanner.segmentBufferKeepingTail())
+	expect(t, "中国/ 有/p3 ", SegmentsToString(scanner.segments, false))
+	expect(t, "十三亿人口", string(scanner.buffer))
+
+	// 没有切开位置的长英文串在缓冲区超过上限时强制切开
+	text = bytes.Repeat([]byte("a"), 1000)
+	scanner = NewScanner(&seg, bytes.NewReader(text))
+	scanner.chunkSize = 30
+	scanner.maxBufferSize = 100
+	total := 0
+	for scanner.Scan() {
+		if len(scanner.buffer) > scanner.maxBufferSize+scanner.chunkSize {
+			t.Fatalf("缓冲区过大 %d", len(scanner.buffer))
+		}
+		segment := scanner.Segment()
+		if segment.start != total {
+			t.Fatalf("分词位置错误 %d %d", segment.start, total)
+		}
+		total = segment.end
+	}
+	expect(t, "1000", total)
+
+	// 读入出错
+	scanner = NewScanner(&seg, iotest.TimeoutReader(bytes.NewReader(content)))
+	scanner.chunkSize = 10
+	for scanner.Scan() {
+	}
+	expect(t, "timeout", scanner.Err())
+}
@@ -0,0 +1,118 @@
+package sego
+
+import (
+	"math"
+)
+
+// 带置信度的分词
+type ScoredSegment struct {
+	Segment
+
+	// 分词本身的路径长度，使用Scorer时为在前一个分词之后的路径长度
+	Cost float32
+
+	// 不使用该分词的最短路径值与最短路径值之差，越大说明该分词越可靠，为零说明
+	// 存在同样好的其它划分。没有其它划分时为+Inf。
+	Margin float32
+}
+
+// 整段文本的分词置信度
+type TextScore struct {
+	// 最短路径值，即分词结果中各分词路径长度之和
+	Cost float32
+
+	// 次短路径值与最短路径值之差，等于各个分词Margin的最小值
+	Margin float32
+}
+
+// 对文本分词，并返回每个分词和整段文本的置信度，分词结果和Segment相同
+//
+// 置信度由完整的分词网格前向和后向各计算一次最短路径得到，比Segment慢。路径值
+// 随文本长度累积，需要句子级别的置信度时可以先用SplitSentences切分句子，再对
+// 每个句子分别调用。
+func (seg *Segmenter) SegmentWithScores(bytes []byte) ([]ScoredSegment, TextScore) {
+	if len(bytes) == 0 {
+		return []ScoredSegment{}, TextScore{}
+	}
+
+	text := splitTextToWords(bytes)
+	l := lattice{keepAll: true}
+	seg.buildLattice(nil, &l, text, false, nil)
+	segments := l.appendBestPath(nil)
+	through := l.bestPathsThrough()
+
+	// 跳转按起始字元的顺序加入，first[i]为第一个从第i个字元开始的跳转
+	first := make([]int, len(text)+1)
+	for i, index := 0, 0; i <= len(text); i++ {
+		for index < len(l.jumpers) && int(l.jumpers[index].start) < i {
+			index++
+		}
+		first[i] = index
+	}
+
+	output := make([]ScoredSegment, len(segments))
+	iSeg := len(segments)
+	inf := float32(math.Inf(1))
+	score := TextScore{Margin: inf}
+	for index := l.bestAt(len(text) - 1); index != -1; index = l.jumpers[index].prev {
+		iSeg--
+		j := &l.jumpers[index]
+		output[iSeg].Segment = segments[iSeg]
+		output[iSeg].Cost = j.minDistance
+		if j.prev != -1 {
+			output[iSeg].Cost -= l.jumpers[j.prev].minDistance
+		}
+
+		// 不使用该分词的路径必然使用另一个覆盖其第一个字元的分词
+		start := int(j.start)
+		alternative := inf
+		for k := first[maxInt(start-seg.dict.maxTokenLength+1, 0)]; k < first[start+1]; k++ {
+			if int32(k) != index &&
+				int(l.jumpers[k].start)+len(l.jumpers[k].token.text) > start &&
+				through[k] < alternative {
+				alternative = through[k]
+			}
+		}
+		output[iSeg].Margin = alternative - through[index]
+		if output[iSeg].Margin < 0 {
+			// 浮点误差
+			output[iSeg].Margin = 0
+		}
+		if output[iSeg].Margin < score.Margin {
+			score.Margin = output[iSeg].Margin
+		}
+		score.Cost += output[iSeg].Cost
+	}
+	return output, score
+}
+
+// 返回经过每个跳转的最短路径值，分词网格必须记录了所有跳转
+func (l *lattice) bestPathsThrough() []float32 {
+	inf := float32(math.Inf(1))
+
+	// after[i]为从第i个跳转之后到文本末尾的最短路径值。跳转按起始字元的顺序
+	// 加入，因此从后向前计算时其后的跳转均已计算完毕。
+	after := make([]float32, len(l.jumpers))
+	for i := len(l.jumpers) - 1; i >= 0; i-- {
+		j := &l.jumpers[i]
+		next := int(j.start) + len(j.token.text)
+		if next == len(l.text) {
+			continue
+		}
+		after[i] = inf
+		for k := i + 1; k < len(l.jumpers) && int(l.jumpers[k].start) <= next; k++ {
+			if int(l.jumpers[k].start) == next {
+				distance := l.distance(next, j.token, l.jumpers[k].token) + after[k]
+				if distance < after[i] {
+					after[i] = distance
+				}
+			}
+		}
+	}
+
+	through := after
+	for i := range l.jumpers {
+		through[i] += l.jumpers[i].minDistance
+	}
+	return through
+}
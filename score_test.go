@@ -0,0 +1,103 @@
+package sego
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// 枚举所有路径，检查各个分词的Margin
+func checkScores(t *testing.T, seg *Segmenter, text []byte) {
+	segments, score := seg.SegmentWithScores(text)
+	expect(t, SegmentsToString(seg.Segment(text), false), SegmentsToString(toSegments(segments), false))
+
+	candidates := seg.Explain(text).Candidates
+	words := splitTextToWords(text)
+	wordIndex := map[int]int{}
+	for i, position := 0, 0; i < len(words); i++ {
+		wordIndex[position] = i
+		position += len(words[i])
+	}
+
+	// 不使用各个分词的路径的最短路径值
+	best := float32(math.Inf(1))
+	alternatives := make([]float32, len(segments))
+	for i := range alternatives {
+		alternatives[i] = float32(math.Inf(1))
+	}
+	var enumerate func(position int, prev *Token, cost float32, used []bool)
+	enumerate = func(position int, prev *Token, cost float32, used []bool) {
+		if position == len(text) {
+			if cost < best {
+				best = cost
+			}
+			for i := range segments {
+				if !used[i] && cost < alternatives[i] {
+					alternatives[i] = cost
+				}
+			}
+			return
+		}
+		for _, candidate := range candidates {
+			if candidate.Start != position {
+				continue
+			}
+			next := make([]bool, len(segments))
+			copy(next, used)
+			for i := range segments {
+				if segments[i].start == candidate.Start && segments[i].end == candidate.End {
+					next[i] = true
+				}
+			}
+			enumerate(candidate.End, candidate.Token,
+				cost+seg.Scorer().Score(words, wordIndex[position], prev, candidate.Token), next)
+		}
+	}
+	enumerate(0, nil, 0, make([]bool, len(segments)))
+
+	near := func(a, b float32) bool {
+		return math.IsInf(float64(a), 1) && math.IsInf(float64(b), 1) || math.Abs(float64(a-b)) < 1e-3
+	}
+	if !near(best, score.Cost) {
+		t.Errorf("路径值错误 %v %v", best, score.Cost)
+	}
+	for i := range segments {
+		if !near(segments[i].Margin, alternatives[i]-best) {
+			t.Errorf("%s的Margin错误 %v %v", segments[i].Token().Text(), segments[i].Margin, alternatives[i]-best)
+		}
+	}
+}
+
+func toSegments(scored []ScoredSegment) []Segment {
+	segments := make([]Segment, len(scored))
+	for i := range scored {
+		segments[i] = scored[i].Segment
+	}
+	return segments
+}
+
+func TestSegmentWithScores(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	segments, score := seg.SegmentWithScores([]byte("中国有十三亿人口"))
+	output := ""
+	for _, segment := range segments {
+		output += fmt.Sprintf("%s/%.2f/%.2f ", segment.Token().Text(), segment.Cost, segment.Margin)
+	}
+	expect(t, "中国/4.03/2.00 有/3.03/2.00 十三亿/7.03/1.03 人口/5.03/1.03 ", output)
+	expect(t, "19.13/1.03", fmt.Sprintf("%.2f/%.2f", score.Cost, score.Margin))
+	checkScores(t, &seg, []byte("中国有十三亿人口"))
+	checkScores(t, &seg, []byte("Made in China 中国人口"))
+
+	segments, score = seg.SegmentWithScores(nil)
+	expect(t, "0", len(segments))
+	expect(t, "{0 0}", score)
+
+	// 使用二元组模型
+	var bigram Segmenter
+	bigram.LoadDictionary("testdata/test_dict3.txt")
+	bigram.LoadBigram("testdata/test_bigram.txt")
+	checkScores(t, &bigram, []byte("研究生命起源"))
+	checkScores(t, &bigram, []byte("研究生命的起源和研究生的生命"))
+}
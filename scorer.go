@@ -0,0 +1,51 @@
+package sego
+
+const (
+	// 词典中没有以某字元开头的单字元分词时，分词器为该字元补加一个伪分词，
+	// 这是伪分词的路径长度
+	UnknownTokenDistance = 32
+)
+
+// Scorer计算分词在Viterbi算法中的路径长度，分词器选择路径长度之和最小的分词
+// 划分。默认的路径长度为Token.Distance()，即log2(总词频/该分词词频)。
+//
+// 通过Segmenter.SetScorer可以替换默认的路径长度，比如惩罚单字元分词、提升
+// 用户词典中分词的优先级，或者按分词长度调整路径长度。
+type Scorer interface {
+	// 返回分词token的路径长度
+	//
+	// text为正在分词的文本段的字元，token从第start个字元开始。prev为候选的前
+	// 一个分词，token位于文本段开头时为nil。分词器对以第start-1个字元结尾的每个
+	// 候选分词各调用一次Score，再从中选出最短路径，因此prev不一定在最终的分词
+	// 结果中，实现不应依赖调用的顺序或者在调用之间保存状态。词典中没有的字元
+	// 对应伪分词，其词性为"x"，路径长度为UnknownTokenDistance。
+	//
+	// 返回值必须为非负数（UnigramScorer对词频占满词典的分词返回零），并且Score
+	// 会被多个goroutine同时调用。
+	Score(text []Text, start int, prev, token *Token) float32
+}
+
+// 一元模型Scorer，路径长度即分词的Token.Distance()，和分词器的默认行为相同
+type UnigramScorer struct{}
+
+func (UnigramScorer) Score(text []Text, start int, prev, token *Token) float32 {
+	return token.distance
+}
+
+// 设置计算路径长度的Scorer，为nil时恢复默认的一元模型
+//
+// 应在载入词典之后、分词之前调用，不能和Segment等分词函数同时调用。重新载入
+// 词典时只清除二元组模型，其它Scorer被保留，引用旧词典中分词的Scorer需要重新
+// 设置。自定义的Scorer会让每个分词考虑以前一个字元结尾的所有候选分词，分词速度
+// 比默认的一元模型稍慢。
+func (seg *Segmenter) SetScorer(scorer Scorer) {
+	seg.scorer = scorer
+}
+
+// 返回分词器使用的Scorer
+func (seg *Segmenter) Scorer() Scorer {
+	if seg.scorer == nil {
+		return UnigramScorer{}
+	}
+	return seg.scorer
+}
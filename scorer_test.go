@@ -0,0 +1,50 @@
+package sego
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// 提升指定分词优先级的Scorer
+type boostScorer struct {
+	boosted map[*Token]bool
+}
+
+func (scorer boostScorer) Score(text []Text, start int, prev, token *Token) float32 {
+	if scorer.boosted[token] {
+		return 0.1
+	}
+	return token.Distance()
+}
+
+func TestScorer(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	text := []byte("中国有十三亿人口")
+	expect(t, "sego.UnigramScorer", fmt.Sprintf("%T", seg.Scorer()))
+
+	seg.SetScorer(UnigramScorer{})
+	expect(t, "中国/ 有/p3 十三亿/ 人口/p12 ", SegmentsToString(seg.Segment(text), false))
+
+	seg.SetScorer(boostScorer{boosted: map[*Token]bool{seg.Dictionary().Lookup([]byte("国有")): true}})
+	expect(t, "中/p1 国有/p9 十三亿/ 人口/p12 ", SegmentsToString(seg.Segment(text), false))
+
+	seg.SetScorer(nil)
+	expect(t, "中国/ 有/p3 十三亿/ 人口/p12 ", SegmentsToString(seg.Segment(text), false))
+}
+
+func TestScorerSurvivesReload(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	seg.SetScorer(UnigramScorer{})
+
+	// 重新载入词典只清除二元组模型，自定义的Scorer被保留
+	seg.LoadDictionary("testdata/test_dict3.txt")
+	expect(t, "sego.UnigramScorer", fmt.Sprintf("%T", seg.Scorer()))
+	expect(t, "true", seg.scorer != nil)
+	scorer := boostScorer{}
+	seg.SetScorer(scorer)
+	seg.LoadDictionaryFromReaders(strings.NewReader("中国 10\n"))
+	expect(t, "sego.boostScorer", fmt.Sprintf("%T", seg.Scorer()))
+}
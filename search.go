@@ -0,0 +1,137 @@
+package sego
+
+// 搜索模式的输出选项
+//
+// SegmentsToString等函数的搜索模式按固定的规则选择输出哪些子分词，而建立索引
+// 和解析查询通常需要不同的粒度：建立索引时输出尽量多的子分词以提高召回率，
+// 解析查询时只输出较长的子分词以提高准确率。SearchOptions的零值输出子分词树中
+// 所有多字元的子分词和原分词。
+type SearchOptions struct {
+	// 输出子分词的最大深度，分词的子分词深度为1，子分词的子分词深度为2，依此类推。
+	// 不大于零时不限制。
+	MaxDepth int
+
+	// 子分词的最小字元数，更短的子分词不输出。单个字元的子分词只由SingleCharacters
+	// 控制。
+	MinLength int
+
+	// 有子分词输出时不输出原分词，即普通模式下的分词。没有子分词输出的原分词总是
+	// 输出，以免丢失文本。
+	ExcludeOriginal bool
+
+	// 是否输出多字元分词中的每个字元，一个汉字或一个英文单词为一个字元
+	SingleCharacters bool
+
+	// 相同文本的分词只输出第一次
+	Dedup bool
+}
+
+// 按options展开分词结果中每个分词的子分词，返回的分词的各种位置均为在整个文本中
+// 的位置，可以直接用于建立带位置的索引
+//
+// 每个分词依次输出其单个字元、子分词和原分词，子分词按子分词树的后序输出，即
+// 先输出较短的子分词。
+func SearchSegments(segs []Segment, options SearchOptions) []Segment {
+	output := []Segment{}
+	var seen map[string]bool
+	if options.Dedup {
+		seen = map[string]bool{}
+	}
+	emit := func(segment Segment) {
+		if seen != nil {
+			text := segment.token.Text()
+			if seen[text] {
+				return
+			}
+			seen[text] = true
+		}
+		output = append(output, segment)
+	}
+
+	minLength := maxInt(options.MinLength, 2)
+	for _, seg := range segs {
+		numOutput := len(output)
+		position := offset{bytes: seg.start, runes: seg.runeStart, utf16: seg.utf16Start}
+
+		// 单个字元
+		if options.SingleCharacters && len(seg.token.text) > 1 {
+			p := position
+			for i := range seg.token.text {
+				character := Segment{start: p.bytes, runeStart: p.runes, utf16Start: p.utf16}
+				p.add(seg.token.text[i])
+				character.end, character.runeEnd, character.utf16End = p.bytes, p.runes, p.utf16
+				character.token = &Token{text: seg.token.text[i : i+1], frequency: 1,
+					distance: UnknownTokenDistance, pos: "x"}
+				emit(character)
+			}
+		}
+
+		// 子分词
+		visitSubSegments(seg.token, position, 1, options.MaxDepth, func(sub Segment) {
+			if len(sub.token.text) >= minLength {
+				emit(sub)
+			}
+		})
+
+		// 原分词
+		if !options.ExcludeOriginal || len(output) == numOutput {
+			emit(seg)
+		}
+	}
+	return output
+}
+
+// 按后序访问token的子分词树中不超过maxDepth层的子分词，子分词的位置转换为在
+// 整个文本中的位置，position为token在整个文本中的位置
+func visitSubSegments(token *Token, position offset, depth, maxDepth int, visit func(Segment)) {
+	if maxDepth > 0 && depth > maxDepth {
+		return
+	}
+	for _, s := range token.segments {
+		if s == nil {
+			continue
+		}
+		sub := *s
+		sub.shift(position)
+		visitSubSegments(sub.token,
+			offset{bytes: sub.start, runes: sub.runeStart, utf16: sub.utf16Start},
+			depth+1, maxDepth, visit)
+		visit(sub)
+	}
+}
+
+// 建立索引的词，即IndexTerms的输出
+type Term struct {
+	Segment
+
+	// 词在文本中的位置，等于它之前的普通模式下可索引分词的个数，从0开始。
+	// 搜索模式下子分词和包含它的分词位置相同，因此词组查询对两种模式都有效。
+	Position int
+}
+
+// 返回分词结果中可以建立索引的词及其位置，只由标点和空白组成的分词被忽略（见
+// Token.IsTerm）。searchMode为true时按SearchOptions的零值展开子分词。
+//
+// 全文检索的分词器（如bleve子包和server的_analyze接口）都应使用这个函数，以保证
+// 索引和查询的分词与位置一致。
+func IndexTerms(segs []Segment, searchMode bool) []Term {
+	expanded := segs
+	if searchMode {
+		expanded = SearchSegments(segs, SearchOptions{})
+	}
+	terms := []Term{}
+	position, current := -1, -1
+	for _, segment := range expanded {
+		if !segment.token.IsTerm() {
+			continue
+		}
+		for current < 0 || segment.start >= segs[current].end {
+			current++
+			if segs[current].token.IsTerm() {
+				position++
+			}
+		}
+		terms = append(terms, Term{Segment: segment, Position: position})
+	}
+	return terms
+}
@@ -0,0 +1,69 @@
+package sego
+
+import (
+	"strconv"
+	"testing"
+)
+
+func searchSegmentsToString(segs []Segment) (output string) {
+	for _, seg := range segs {
+		start, end := seg.Offsets(RuneOffset)
+		output += seg.Token().Text() + "[" + strconv.Itoa(start) + "," + strconv.Itoa(end) + ") "
+	}
+	return
+}
+
+func TestSearchSegments(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	segments := seg.Segment([]byte("中国有十三亿"))
+
+	expect(t, "中国[0,2) 有[2,3) 十三[3,5) 十三亿[3,6) ",
+		searchSegmentsToString(SearchSegments(segments, SearchOptions{})))
+	expect(t, "中国[0,2) 有[2,3) 十三亿[3,6) ",
+		searchSegmentsToString(SearchSegments(segments, SearchOptions{MinLength: 3})))
+	expect(t, "中国[0,2) 有[2,3) 十三[3,5) ",
+		searchSegmentsToString(SearchSegments(segments, SearchOptions{ExcludeOriginal: true})))
+	expect(t, "中[0,1) 国[1,2) 中国[0,2) 有[2,3) 十[3,4) 三[4,5) 亿[5,6) 十三[3,5) 十三亿[3,6) ",
+		searchSegmentsToString(SearchSegments(segments, SearchOptions{SingleCharacters: true})))
+	expect(t, "中[0,1) 国[1,2) 有[2,3) 十[3,4) 三[4,5) 亿[5,6) ",
+		searchSegmentsToString(SearchSegments(segments,
+			SearchOptions{SingleCharacters: true, MinLength: 10, ExcludeOriginal: true})))
+
+	// 子分词树的深度
+	var deep Segmenter
+	deep.LoadDictionary("testdata/test_dict4.txt")
+	segments = deep.Segment([]byte("中华人民共和国"))
+	expect(t, "中华/nz 人民/n 共和/nz 国/n 共和国/ns 人民共和国/nt 中华人民共和国/ns ",
+		SegmentsToString(segments, true))
+	expect(t, "中华[0,2) 人民[2,4) 共和[4,6) 共和国[4,7) 人民共和国[2,7) 中华人民共和国[0,7) ",
+		searchSegmentsToString(SearchSegments(segments, SearchOptions{})))
+	expect(t, "中华[0,2) 人民共和国[2,7) 中华人民共和国[0,7) ",
+		searchSegmentsToString(SearchSegments(segments, SearchOptions{MaxDepth: 1})))
+	expect(t, "中华[0,2) 人民[2,4) 共和国[4,7) 人民共和国[2,7) ",
+		searchSegmentsToString(SearchSegments(segments, SearchOptions{MaxDepth: 2, ExcludeOriginal: true})))
+
+	// 去重
+	segments = seg.Segment([]byte("中国中国"))
+	expect(t, "中国[0,2) 中国[2,4) ", searchSegmentsToString(SearchSegments(segments, SearchOptions{})))
+	expect(t, "中国[0,2) ", searchSegmentsToString(SearchSegments(segments, SearchOptions{Dedup: true})))
+}
+
+func indexTermsToString(terms []Term) (output string) {
+	for _, term := range terms {
+		output += term.Token().Text() + "[" + strconv.Itoa(term.Start()) + "," +
+			strconv.Itoa(term.End()) + ")@" + strconv.Itoa(term.Position) + " "
+	}
+	return
+}
+
+func TestIndexTerms(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	segments := seg.Segment([]byte("中国，有十三亿"))
+
+	expect(t, "中国[0,6)@0 有[9,12)@1 十三亿[12,21)@2 ", indexTermsToString(IndexTerms(segments, false)))
+	expect(t, "中国[0,6)@0 有[9,12)@1 十三[12,18)@2 十三亿[12,21)@2 ",
+		indexTermsToString(IndexTerms(segments, true)))
+	expect(t, "0", strconv.Itoa(len(IndexTerms(seg.Segment([]byte("，。")), true))))
+}
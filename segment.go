@@ -8,6 +8,12 @@ type Segment struct {
 	// 分词在文本中的结束字节位置（不包括该位置）
 	end int
 
+	// 分词在文本中的起始和结束字符位置，按Unicode码点计数
+	runeStart, runeEnd int
+
+	// 分词在文本中的起始和结束位置，按UTF-16编码单元计数
+	utf16Start, utf16End int
+
 	// 分词信息
 	token *Token
 }
@@ -22,6 +28,27 @@ func (s *Segment) End() int {
 	return s.end
 }
 
+// 返回分词在文本中的起始字符位置，即之前的Unicode码点数
+func (s *Segment) RuneStart() int {
+	return s.runeStart
+}
+
+// 返回分词在文本中的结束字符位置（不包括该位置）
+func (s *Segment) RuneEnd() int {
+	return s.runeEnd
+}
+
+// 返回分词在文本中按UTF-16编码单元计数的起始位置，JavaScript和Java中字符串
+// 的下标即为此位置
+func (s *Segment) UTF16Start() int {
+	return s.utf16Start
+}
+
+// 返回分词在文本中按UTF-16编码单元计数的结束位置（不包括该位置）
+func (s *Segment) UTF16End() int {
+	return s.utf16End
+}
+
 // 返回分词信息
 func (s *Segment) Token() *Token {
 	return s.token
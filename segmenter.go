@@ -3,7 +3,9 @@ package sego
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"io"
 	"log"
 	"math"
 	"os"
@@ -19,13 +21,9 @@ const (
 
 // 分词器结构体
 type Segmenter struct {
-	dict *Dictionary
-}
-
-// 该结构体用于记录Viterbi算法中某字元处的向前分词跳转信息
-type jumper struct {
-	minDistance float32
-	token       *Token
+	dict   *Dictionary
+	scorer Scorer
+	limits Limits
 }
 
 // 返回分词器使用的词典
@@ -41,8 +39,12 @@ func (seg *Segmenter) Dictionary() *Dictionary {
 //
 // 词典的格式为（每个分词一行）：
 //	分词文本 频率 词性
+//
+// LoadBigram载入的二元组模型引用旧词典中的分词，因此载入词典时被清除。SetScorer
+// 设置的其它Scorer被保留，如果它引用了旧词典中的分词，调用者需要重新创建并设置。
 func (seg *Segmenter) LoadDictionary(files string) {
 	seg.dict = NewDictionary()
+	seg.clearBigram()
 	for _, file := range strings.Split(files, ",") {
 		log.Printf("载入sego词典 %s", file)
 		dictFile, err := os.Open(file)
@@ -50,47 +52,70 @@ func (seg *Segmenter) LoadDictionary(files string) {
 		if err != nil {
 			log.Fatalf("无法载入字典文件 \"%s\" \n", file)
 		}
+		seg.loadTokens(dictFile)
+	}
+	seg.initTokens()
+	log.Println("sego词典载入完毕")
+}
 
-		reader := bufio.NewReader(dictFile)
-		var text string
-		var freqText string
-		var frequency int
-		var pos string
-
-		// 逐行读入分词
-		for {
-			size, _ := fmt.Fscanln(reader, &text, &freqText, &pos)
-
-			if size == 0 {
-				// 文件结束
-				break
-			} else if size < 2 {
-				// 无效行
-				continue
-			} else if size == 2 {
-				// 没有词性标注时设为空字符串
-				pos = ""
-			}
+// 从多个reader中载入词典，词典的格式和LoadDictionary相同，排在前面的reader
+// 优先载入分词
+//
+// 可以用于载入不在文件中的词典，比如程序运行时加入的用户词典。和LoadDictionary
+// 相同，载入词典时清除二元组模型，保留其它Scorer。
+func (seg *Segmenter) LoadDictionaryFromReaders(readers ...io.Reader) {
+	seg.dict = NewDictionary()
+	seg.clearBigram()
+	for _, r := range readers {
+		seg.loadTokens(r)
+	}
+	seg.initTokens()
+}
 
-			// 解析词频
-			var err error
-			frequency, err = strconv.Atoi(freqText)
-			if err != nil {
-				continue
-			}
+// 从r中逐行读入分词并加入词典
+func (seg *Segmenter) loadTokens(r io.Reader) {
+	reader := bufio.NewReader(r)
+	var text string
+	var freqText string
+	var frequency int
+	var pos string
+
+	// 逐行读入分词
+	for {
+		size, _ := fmt.Fscanln(reader, &text, &freqText, &pos)
+
+		if size == 0 {
+			// 文件结束
+			break
+		} else if size < 2 {
+			// 无效行
+			continue
+		} else if size == 2 {
+			// 没有词性标注时设为空字符串
+			pos = ""
+		}
 
-			// 过滤频率太小的词
-			if frequency < minTokenFrequency {
-				continue
-			}
+		// 解析词频
+		var err error
+		frequency, err = strconv.Atoi(freqText)
+		if err != nil {
+			continue
+		}
 
-			// 将分词添加到字典中
-			words := splitTextToWords([]byte(text))
-			token := Token{text: words, frequency: frequency, pos: pos}
-			seg.dict.addToken(token)
+		// 过滤频率太小的词
+		if frequency < minTokenFrequency {
+			continue
 		}
+
+		// 将分词添加到字典中
+		words := splitTextToWords([]byte(text))
+		token := Token{text: words, frequency: frequency, pos: pos}
+		seg.dict.addToken(token)
 	}
+}
 
+// 词典中的分词全部载入后计算分词的路径值和子分词
+func (seg *Segmenter) initTokens() {
 	// 计算每个分词的路径值，路径值含义见Token结构体的注释
 	logTotalFrequency := float32(math.Log2(float64(seg.dict.totalFrequency)))
 	for i := range seg.dict.tokens {
@@ -101,7 +126,7 @@ func (seg *Segmenter) LoadDictionary(files string) {
 	// 对每个分词进行细致划分，用于搜索引擎模式，该模式用法见Token结构体的注释。
 	for i := range seg.dict.tokens {
 		token := &seg.dict.tokens[i]
-		segments := seg.segmentWords(token.text, true)
+		segments := seg.segmentWords(token.text, true, nil)
 
 		// 计算需要添加的子分词数目
 		numTokensToAdd := 0
@@ -121,8 +146,6 @@ func (seg *Segmenter) LoadDictionary(files string) {
 			}
 		}
 	}
-
-	log.Println("sego词典载入完毕")
 }
 
 // 对文本分词
@@ -156,86 +179,64 @@ func (seg *Segmenter) internalSegment(bytes []byte, searchMode bool) []Segment {
 	// 划分字元
 	text := splitTextToWords(bytes)
 
-	return seg.segmentWords(text, searchMode)
+	return seg.segmentWords(text, searchMode, nil)
 }
 
-func (seg *Segmenter) segmentWords(text []Text, searchMode bool) []Segment {
+// 对字元组分词，constraints不为nil时分词结果满足其中的约束
+func (seg *Segmenter) segmentWords(
+	text []Text, searchMode bool, constraints *wordConstraints) []Segment {
 	// 搜索模式下该分词已无继续划分可能的情况
 	if searchMode && len(text) == 1 {
 		return []Segment{}
 	}
 
-	// jumpers定义了每个字元处的向前跳转信息，包括这个跳转对应的分词，
-	// 以及从文本段开始到该字元的最短路径值
-	jumpers := make([]jumper, len(text))
+	var l lattice
+	seg.buildLattice(nil, &l, text, searchMode, constraints)
+	return l.appendBestPath(nil)
+}
 
-	tokens := make([]*Token, seg.dict.maxTokenLength)
+// 建立分词网格，记录所有候选分词对应的向前跳转
+//
+// ctx不为nil时每处理cancelCheckInterval个字元检查一次ctx是否被取消，被取消时
+// 返回ctx.Err()，此时分词网格不完整。ctx为nil时总是返回nil。
+func (seg *Segmenter) buildLattice(ctx context.Context,
+	l *lattice, text []Text, searchMode bool, constraints *wordConstraints) error {
+	l.reset(text, seg.scorer, seg.dict.maxTokenLength)
+	tokens := l.tokens
 	for current := 0; current < len(text); current++ {
-		// 找到前一个字元处的最短路径，以便计算后续路径值
-		var baseDistance float32
-		if current == 0 {
-			// 当本字元在文本首部时，基础距离应该是零
-			baseDistance = 0
-		} else {
-			baseDistance = jumpers[current-1].minDistance
+		if ctx != nil && current%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+
+		// 必须合并的字元区间只能作为一个整体分词
+		if merge := constraints.merge(current); merge != nil {
+			if merge.start == current {
+				l.addJumper(current, merge.end, merge.token)
+			}
+			continue
 		}
 
 		// 寻找所有以当前字元开头的分词
 		numTokens := seg.dict.lookupTokens(
 			text[current:minInt(current+seg.dict.maxTokenLength, len(text))], tokens)
 
-		// 对所有可能的分词，更新分词结束字元处的跳转信息
+		// 对所有可能的分词，在分词结束字元处添加跳转
 		for iToken := 0; iToken < numTokens; iToken++ {
 			location := current + len(tokens[iToken].text) - 1
-			if !searchMode || current != 0 || location != len(text)-1 {
-				updateJumper(&jumpers[location], baseDistance, tokens[iToken])
+			if (!searchMode || current != 0 || location != len(text)-1) &&
+				constraints.allows(current, location) {
+				l.addJumper(current, location, tokens[iToken])
 			}
 		}
 
 		// 当前字元没有对应分词时补加一个伪分词
 		if numTokens == 0 || len(tokens[0].text) > 1 {
-			updateJumper(&jumpers[current], baseDistance,
-				&Token{text: []Text{text[current]}, frequency: 1, distance: 32, pos: "x"})
+			l.addJumper(current, current, l.unknownToken(current))
 		}
 	}
-
-	// 从后向前扫描第一遍得到需要添加的分词数目
-	numSeg := 0
-	for index := len(text) - 1; index >= 0; {
-		location := index - len(jumpers[index].token.text) + 1
-		numSeg++
-		index = location - 1
-	}
-
-	// 从后向前扫描第二遍添加分词到最终结果
-	outputSegments := make([]Segment, numSeg)
-	for index := len(text) - 1; index >= 0; {
-		location := index - len(jumpers[index].token.text) + 1
-		numSeg--
-		outputSegments[numSeg].token = jumpers[index].token
-		index = location - 1
-	}
-
-	// 计算各个分词的字节位置
-	bytePosition := 0
-	for iSeg := 0; iSeg < len(outputSegments); iSeg++ {
-		outputSegments[iSeg].start = bytePosition
-		bytePosition += textSliceByteLength(outputSegments[iSeg].token.text)
-		outputSegments[iSeg].end = bytePosition
-	}
-	return outputSegments
-}
-
-// 更新跳转信息:
-// 	1. 当该位置从未被访问过时(jumper.minDistance为零的情况)，或者
-//	2. 当该位置的当前最短路径大于新的最短路径时
-// 将当前位置的最短路径值更新为baseDistance加上新分词的概率
-func updateJumper(jumper *jumper, baseDistance float32, token *Token) {
-	newDistance := baseDistance + token.distance
-	if jumper.minDistance == 0 || jumper.minDistance > newDistance {
-		jumper.minDistance = newDistance
-		jumper.token = token
-	}
+	return nil
 }
 
 // 取两整数较小值
@@ -256,7 +257,14 @@ func maxInt(a, b int) int {
 
 // 将文本划分成字元
 func splitTextToWords(text Text) []Text {
-	output := make([]Text, 0, len(text)/3)
+	return appendTextToWords(make([]Text, 0, len(text)/3), text, nil)
+}
+
+// 将文本划分成字元，并添加到output之后
+//
+// 含大写字母的英文词需要转化为小写拷贝。lower不为nil时拷贝添加到*lower之后，
+// 调用者需保证其剩余容量不小于text的长度；lower为nil时为每个英文词分配内存。
+func appendTextToWords(output []Text, text Text, lower *[]byte) []Text {
 	current := 0
 	inAlphanumeric := true
 	alphanumericStart := 0
@@ -272,7 +280,7 @@ func splitTextToWords(text Text) []Text {
 			if inAlphanumeric {
 				inAlphanumeric = false
 				if current != 0 {
-					output = append(output, toLower(text[alphanumericStart:current]))
+					output = append(output, lowerWord(text[alphanumericStart:current], lower))
 				}
 			}
 			output = append(output, text[current:current+size])
@@ -283,13 +291,39 @@ func splitTextToWords(text Text) []Text {
 	// 处理最后一个字元是英文的情况
 	if inAlphanumeric {
 		if current != 0 {
-			output = append(output, toLower(text[alphanumericStart:current]))
+			output = append(output, lowerWord(text[alphanumericStart:current], lower))
 		}
 	}
 
 	return output
 }
 
+// 将英文词转化为小写，不含大写字母时直接返回原文本，否则返回小写拷贝。
+// 拷贝的内存分配见appendTextToWords的注释。
+func lowerWord(text []byte, lower *[]byte) []byte {
+	hasUpper := false
+	for _, t := range text {
+		if t >= 'A' && t <= 'Z' {
+			hasUpper = true
+			break
+		}
+	}
+	if !hasUpper {
+		return text
+	} else if lower == nil {
+		return toLower(text)
+	}
+
+	start := len(*lower)
+	for _, t := range text {
+		if t >= 'A' && t <= 'Z' {
+			t = t - 'A' + 'a'
+		}
+		*lower = append(*lower, t)
+	}
+	return (*lower)[start:len(*lower):len(*lower)]
+}
+
 // 将英文词转化为小写
 func toLower(text []byte) []byte {
 	output := make([]byte, len(text))
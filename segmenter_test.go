@@ -1,6 +1,7 @@
 package sego
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -52,6 +53,17 @@ func TestSegment(t *testing.T) {
 	expect(t, "24", segments[3].end)
 }
 
+func TestLoadDictionaryFromReaders(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionaryFromReaders(
+		strings.NewReader("十三亿 100 m\n人口 1 n\n"),
+		strings.NewReader("中国 10 ns\n十三亿 2 mq\n人口 10 n\n"))
+	expect(t, "3", seg.dict.NumTokens())
+	expect(t, "m", seg.dict.Lookup([]byte("十三亿")).Pos())
+	segments := seg.Segment([]byte("中国有十三亿人口"))
+	expect(t, "中国/ns 有/x 十三亿/m 人口/n ", SegmentsToString(segments, false))
+}
+
 func TestLargeDictionary(t *testing.T) {
 	prodSeg.LoadDictionary("data/dictionary.txt")
 	expect(t, "中国/ns 人口/n ", SegmentsToString(prodSeg.Segment(
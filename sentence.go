@@ -0,0 +1,129 @@
+package sego
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 将文本切分为句子，返回各个句子的字节区间
+//
+// 句子在以下位置结束：
+//  1. 句末标点。！？；…以及对应的半角标点之后，连续的句末标点（比如"？！"和
+//     省略号"……"）以及紧随其后的右引号和右括号属于同一个句子；半角引号"和'
+//     不区分左右，只在句子中有未配对的同一引号时才作为右引号属于该句子；半角
+//     句点只在其后是空白或文本末尾时结束句子，以免切开小数和网址
+//  2. 换行处
+//
+// 句子两端的空白不包括在区间内，只有空白的句子被忽略。
+func SplitSentences(text []byte) []Span {
+	spans := []Span{}
+	start := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRune(text[i:])
+		switch {
+		case isLineBreak(r):
+			spans = appendSentence(spans, text, start, i)
+			start = i + size
+		case isSentenceTerminator(r) && (r != '.' || isSentenceEnd(text[i+size:])):
+			// 吸收连续的句末标点和其后的右引号、右括号
+			end := i + size
+			for end < len(text) {
+				r, size := utf8.DecodeRune(text[end:])
+				if !isSentenceTerminator(r) && !isClosingPunct(r) && !closesQuote(text[start:end], r) {
+					break
+				}
+				end += size
+			}
+			spans = appendSentence(spans, text, start, end)
+			start = end
+			i = end
+			continue
+		}
+		i += size
+	}
+	return appendSentence(spans, text, start, len(text))
+}
+
+// 对文本按句子分词，返回每个句子的分词结果，分词的各种位置均为在整个文本中的
+// 位置。句子的划分见SplitSentences。
+//
+// 每个句子单独分词，因此分词不会跨越句子边界，大段文本的Viterbi路径值也不会
+// 累积得过大。
+func (seg *Segmenter) SegmentSentences(bytes []byte) [][]Segment {
+	spans := SplitSentences(bytes)
+	sentences := make([][]Segment, len(spans))
+	var position offset
+	previous := 0
+	for i, span := range spans {
+		position.add(bytes[previous:span.Start])
+		sentences[i] = seg.Segment(bytes[span.Start:span.End])
+		shiftSegments(sentences[i], position)
+		position.add(bytes[span.Start:span.End])
+		previous = span.End
+	}
+	return sentences
+}
+
+// 去掉两端的空白后将句子加入spans
+func appendSentence(spans []Span, text []byte, start, end int) []Span {
+	for start < end {
+		r, size := utf8.DecodeRune(text[start:end])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		start += size
+	}
+	for end > start {
+		r, size := utf8.DecodeLastRune(text[start:end])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		end -= size
+	}
+	if start == end {
+		return spans
+	}
+	return append(spans, Span{start, end})
+}
+
+// 是否为句末标点
+func isSentenceTerminator(r rune) bool {
+	switch r {
+	case '。', '！', '？', '；', '…', '｡', '.', '!', '?', ';':
+		return true
+	}
+	return false
+}
+
+// 是否为可以跟在句末标点之后的右引号或右括号，不包括不区分左右的半角引号
+func isClosingPunct(r rune) bool {
+	switch r {
+	case '”', '’', '」', '』', '）', '】', '》', '〉', '〕', ')', ']':
+		return true
+	}
+	return false
+}
+
+// 半角引号r是否为句子sentence中未配对引号的右引号，即sentence中有奇数个r。
+// 这样句末之后的左引号（他说完了。"下一句"）和撇号不会被并入前一个句子。
+func closesQuote(sentence []byte, r rune) bool {
+	if r != '"' && r != '\'' {
+		return false
+	}
+	return bytes.Count(sentence, []byte{byte(r)})%2 == 1
+}
+
+// 是否为换行符
+func isLineBreak(r rune) bool {
+	return r == '\n' || r == '\r' || r == '\u2028' || r == '\u2029'
+}
+
+// 半角句点之后的文本是否说明句子已经结束
+func isSentenceEnd(text []byte) bool {
+	if len(text) == 0 {
+		return true
+	}
+	r, _ := utf8.DecodeRune(text)
+	return unicode.IsSpace(r) || isSentenceTerminator(r) || isClosingPunct(r) || r == '"' || r == '\''
+}
@@ -0,0 +1,49 @@
+package sego
+
+import (
+	"testing"
+)
+
+func sentencesToString(text string) string {
+	output := ""
+	for _, span := range SplitSentences([]byte(text)) {
+		output += "[" + text[span.Start:span.End] + "]"
+	}
+	return output
+}
+
+func TestSplitSentences(t *testing.T) {
+	expect(t, "", sentencesToString(""))
+	expect(t, "", sentencesToString(" \n\r\n "))
+	expect(t, "[中国有十三亿人口。][人口很多！]", sentencesToString("中国有十三亿人口。人口很多！"))
+	expect(t, "[他说：“你好吗？”][我说：“很好。”]", sentencesToString("他说：“你好吗？”我说：“很好。”"))
+	expect(t, "[真的吗？！][不会吧……][好吧]", sentencesToString("真的吗？！不会吧……好吧"))
+	expect(t, "[第一行][第二行][第三行]", sentencesToString("第一行\r\n第二行\n\n  第三行  "))
+	expect(t, "[先这样；][再那样]", sentencesToString("先这样；再那样"))
+	expect(t, "[Pi is 3.14 (see example.com).][Really?!][Wait...][ok]",
+		sentencesToString("Pi is 3.14 (see example.com). Really?! Wait... ok"))
+	expect(t, "[(It works.)][Yes]", sentencesToString("(It works.) Yes"))
+
+	// 半角引号只在关闭句子中的引号时属于该句子
+	expect(t, "[他说完了。][\"下一句\"]", sentencesToString("他说完了。\"下一句\""))
+	expect(t, "[他说：\"完了。\"][下一句]", sentencesToString("他说：\"完了。\"下一句"))
+	expect(t, "[He said \"done.\"][Then 'ok.'][Yes]", sentencesToString("He said \"done.\" Then 'ok.' Yes"))
+	expect(t, "[done.][' s]", sentencesToString("done.' s"))
+}
+
+func TestSegmentSentences(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+
+	text := []byte("😀中国有十三亿人口。\n 人口很多！")
+	sentences := seg.SegmentSentences(text)
+	expect(t, "2", len(sentences))
+	expect(t, "😀/x 中国/ 有/p3 十三亿/ 人口/p12 。/x ", SegmentsToString(sentences[0], false))
+	expect(t, "人口/p12 很/x 多/x ！/x ", SegmentsToString(sentences[1], false))
+	expect(t, "[{33 39} {39 42} {42 45} {45 48}]", SegmentSpans(sentences[1], ByteOffset))
+	expect(t, "[{12 14} {14 15} {15 16} {16 17}]", SegmentSpans(sentences[1], RuneOffset))
+	expect(t, "[{13 15} {15 16} {16 17} {17 18}]", SegmentSpans(sentences[1], UTF16Offset))
+	for _, sentence := range sentences {
+		checkOffsets(t, text, sentence)
+	}
+}
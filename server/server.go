@@ -5,16 +5,62 @@ sego分词服务器同时提供了两种模式：
 	"/"	分词演示网页
 	"/json"	JSON格式的RPC服务
 		输入：
-			POST或GET模式输入text参数
+			POST或GET模式输入text参数，以及可选的参数
+				mode		分词模式，normal（默认）、search或full
+				offsets		start和end的单位，byte（默认）、rune或utf16
+				pos		只输出这些词性的分词，以逗号分隔
+				exclude_pos	不输出这些词性的分词，以逗号分隔
+				stop_words	为true时去除停用词（需要-stop_words参数）
+			也可以POST JSON格式，参数同上，pos和exclude_pos为字符串数组：
+			{"text":"服务器指令", "mode":"search", "pos":["n"]}
+			texts为字符串数组时批量分词，请求体直接为字符串数组时相同：
+			{"texts":["服务器指令", "..."], "offsets":"rune"}
 		输出JSON格式：
 			{
 				segments:[
-					{"text":"服务器", "pos":"n"},
-					{"text":"指令", "pos":"n"},
+					{"text":"服务器", "pos":"n", "start":0, "end":9, "frequency":1094},
+					{"text":"指令", "pos":"n", "start":9, "end":15, "frequency":1037},
 					...
 				]
 			}
+			其中start和end默认为分词在文本中的字节位置。批量分词时输出
+			{"results":[{"segments":[...]}, ...]}，每段文本一个结果。
+			参数错误时返回4xx状态码和{"error":"..."}。
+	"/_analyze"	和Elasticsearch的_analyze接口兼容的分析服务，也可以用"/索引名/_analyze"访问
+		输入：
+			GET或POST JSON格式的请求体，或者GET模式输入analyzer和text参数
+			{"analyzer":"sego", "text":"服务器指令"}
+			analyzer为sego（普通模式，默认）或sego_search（搜索模式），text可以是字符串数组
+			不支持tokenizer、filter、field、explain等其它参数，出现时返回400
+		输出JSON格式：
+			{
+				tokens:[
+					{"token":"服务器", "start_offset":0, "end_offset":3, "type":"n", "position":0},
+					...
+				]
+			}
+			其中start_offset和end_offset为按UTF-16编码单元计数的位置，和Elasticsearch相同
+
+	"/admin/"	词典管理接口，需要用-admin_token参数设置管理密钥，并在请求的
+			Authorization头中提供"Bearer 管理密钥"
+		GET /admin/dictionary		词典的统计信息
+		GET /admin/words?text=词		查找词典中的词
+		POST /admin/words		加入词，请求体为JSON格式的{"text":"词",
+						"frequency":100, "pos":"n"}或其数组
+		DELETE /admin/words?text=词	删除词
+		POST /admin/reload		从文件重新载入词典和用户词典
+		除查找外均输出修改后的词典统计信息：
+			{"num_tokens":..., "total_frequency":..., "max_token_length":...,
+			 "user_words":..., "removed_words":...}
+		设置了-user_dict参数时修改保存在用户词典文件中，被删除的词频率为0。
+		每次修改都会在管理锁内重新载入整个词典，默认词典需要数秒，新词典
+		替换旧词典之前内存占用约为平时的两倍，因此加入多个词时应一次提交。
+	"/metrics"	Prometheus文本格式的监控指标：请求数、请求耗时、分词的字节数、
+			词典大小和词典载入状态
+	"/healthz"	服务器进程存活时返回200
+	"/readyz"	词典载入完毕、可以分词时返回200，否则返回503
 
+访问日志为每行一个JSON对象，默认输出到标准输出，见-access_log参数。
 
 测试服务器见 http://sego.weiboglass.com
 
@@ -23,6 +69,10 @@ sego分词服务器同时提供了两种模式：
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -30,47 +80,1166 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"os"
 	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
 )
 
 var (
-	host      = flag.String("host", "", "HTTP服务器主机名")
-	port      = flag.Int("port", 8080, "HTTP服务器端口")
-	dict      = flag.String("dict", "../data/dictionary.txt", "词典文件")
-	staticFolder = flag.String("static_folder", "static", "静态页面存放的目录")
-	segmenter = sego.Segmenter{}
+	host          = flag.String("host", "", "HTTP服务器主机名")
+	port          = flag.Int("port", 8080, "HTTP服务器端口")
+	dict          = flag.String("dict", "../data/dictionary.txt", "词典文件")
+	staticFolder  = flag.String("static_folder", "static", "静态页面存放的目录")
+	maxTextBytes  = flag.Int("max_text_bytes", 1<<20, "待分词文本的最大字节数，0表示不限制")
+	maxBodyBytes  = flag.Int("max_body_bytes", 10<<20, "请求体的最大字节数，0表示不限制")
+	maxSegments   = flag.Int("max_segments", 0, "分词结果的最大分词数目，0表示不限制")
+	maxBatchTexts = flag.Int("max_batch_texts", 1000, "批量分词时一次请求的最多文本数，0表示不限制")
+	stopWordsFile = flag.String("stop_words", "", "停用词文件，每行一个停用词")
+	userDict      = flag.String("user_dict", "", "用户词典文件，优先于词典文件载入，管理接口的修改保存在其中")
+	accessLogFile = flag.String("access_log", "-", "JSON格式的访问日志文件，\"-\"表示标准输出，为空时不输出")
+	adminToken    = flag.String("admin_token", "", "管理接口的密钥，为空时使用环境变量SEGO_ADMIN_TOKEN，都为空时不开放管理接口。"+
+		"每次修改词典都会重新载入整个词典，默认词典需要数秒，期间内存占用约为平时的两倍")
+
+	// 当前使用的分词器，修改词典时整体替换，用currentSegmenter读取
+	segmenter     *sego.Segmenter
+	segmenterLock sync.RWMutex
+
+	// 停用词，没有指定停用词文件时为nil
+	stopWords map[string]bool
 )
 
-type JsonResponse struct {
-	Segments []*Segment `json:"segments"`
+// /json接口的请求参数，GET模式或表单POST时从同名参数中读取，pos和exclude_pos
+// 以逗号分隔；POST JSON时为下面的JSON对象，或者是字符串数组（即texts）。JSON
+// 对象中有未知的字段时返回400。
+type JsonRequest struct {
+	// 要分词的文本，不能为空。texts不为nil时批量分词，此时不能有text
+	Text  string   `json:"text"`
+	Texts []string `json:"texts"`
+
+	// 分词模式：normal（默认）为普通模式；search为搜索模式，同时输出分词的子
+	// 分词；full为全模式，输出文本中所有的词典分词和字元，分词之间可以重叠
+	Mode string `json:"mode"`
+
+	// start和end的单位：byte（默认）为字节，rune为Unicode码点，utf16为UTF-16
+	// 编码单元
+	Offsets string `json:"offsets"`
+
+	// 只输出这些词性的分词，为空时不限制
+	Pos []string `json:"pos"`
+
+	// 不输出这些词性的分词
+	ExcludePos []string `json:"exclude_pos"`
+
+	// 是否去除停用词，停用词由-stop_words参数指定
+	StopWords bool `json:"stop_words"`
+}
+
+// 批量分词的输出，每段文本一个结果
+type JsonBatchResponse struct {
+	Results []sego.JSONSegments `json:"results"`
 }
 
-type Segment struct {
-	Text string `json:"text"`
-	Pos  string `json:"pos"`
+// 解析后的分词选项
+type jsonOptions struct {
+	mode       string
+	unit       sego.OffsetUnit
+	pos        map[string]bool
+	excludePos map[string]bool
+	stopWords  bool
 }
 
 func JsonRpcServer(w http.ResponseWriter, req *http.Request) {
-	// 得到要分词的文本
-	text := req.URL.Query().Get("text")
-	if text == "" {
-		text = req.PostFormValue("text")
+	if req.Method != http.MethodGet && req.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		writeJsonError(w, http.StatusMethodNotAllowed, "method "+req.Method+" is not allowed")
+		return
+	}
+
+	// 得到要分词的文本和分词选项
+	request, status, err := parseJsonRequest(w, req)
+	if err != nil {
+		writeJsonError(w, status, err.Error())
+		return
+	}
+	options, err := parseJsonOptions(request)
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	batch := request.Texts != nil
+	texts := request.Texts
+	switch {
+	case batch && request.Text != "":
+		writeJsonError(w, http.StatusBadRequest, "text and texts can not be used together")
+		return
+	case batch && len(texts) == 0:
+		writeJsonError(w, http.StatusBadRequest, "texts is empty")
+		return
+	case !batch && request.Text == "":
+		writeJsonError(w, http.StatusBadRequest, "text is missing")
+		return
+	case !batch:
+		texts = []string{request.Text}
+	}
+	if *maxBatchTexts > 0 && len(texts) > *maxBatchTexts {
+		writeJsonError(w, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("too many texts, at most %d are allowed", *maxBatchTexts))
+		return
+	}
+
+	seg := currentSegmenter()
+	if seg == nil {
+		writeJsonError(w, http.StatusServiceUnavailable, "dictionary is loading")
+		return
 	}
 
-	// 分词
-	segments := segmenter.Segment([]byte(text))
+	// 分词，客户端断开连接时停止
+	results, err := segmentTexts(req.Context(), seg, texts, options)
+	if err == sego.ErrTextTooLong || err == sego.ErrTooManySegments {
+		writeJsonError(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	} else if err != nil {
+		writeJsonError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	for _, text := range texts {
+		metrics.addSegmentedBytes(len(text))
+	}
 
 	// 整理为输出格式
-	ss := []*Segment{}
-	for _, segment := range segments {
-		ss = append(ss, &Segment{Text: segment.Token().Text(), Pos: segment.Token().Pos()})
+	var response []byte
+	if batch {
+		response, _ = json.Marshal(&JsonBatchResponse{Results: results})
+	} else {
+		response, _ = json.Marshal(&results[0])
 	}
-	response, _ := json.Marshal(&JsonResponse{Segments: ss})
 
 	w.Header().Set("Content-Type", "application/json")
 	io.WriteString(w, string(response))
 }
 
+// 从请求参数或JSON请求体中读取请求，出错时同时返回HTTP状态码
+func parseJsonRequest(w http.ResponseWriter, req *http.Request) (*JsonRequest, int, error) {
+	request := &JsonRequest{}
+	if req.Method == http.MethodPost &&
+		strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		body, err := io.ReadAll(limitBody(w, req))
+		if isBodyTooLarge(err) {
+			return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("request body is too large")
+		} else if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		body = bytes.TrimSpace(body)
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.DisallowUnknownFields()
+		if len(body) > 0 && body[0] == '[' {
+			err = decoder.Decode(&request.Texts)
+		} else {
+			err = decoder.Decode(request)
+		}
+		if err == nil && decoder.More() {
+			err = fmt.Errorf("unexpected data after the JSON value")
+		}
+		if err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("request body is invalid: %v", err)
+		}
+		return request, 0, nil
+	}
+
+	// GET参数或表单
+	if req.Body != nil && *maxBodyBytes > 0 {
+		req.Body = http.MaxBytesReader(w, req.Body, int64(*maxBodyBytes))
+	}
+	if err := req.ParseForm(); isBodyTooLarge(err) {
+		return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("request body is too large")
+	} else if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	request.Text = req.FormValue("text")
+	request.Mode = req.FormValue("mode")
+	request.Offsets = req.FormValue("offsets")
+	request.Pos = splitParameter(req.Form["pos"])
+	request.ExcludePos = splitParameter(req.Form["exclude_pos"])
+	if value := req.FormValue("stop_words"); value != "" {
+		stopWords, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("invalid stop_words %q", value)
+		}
+		request.StopWords = stopWords
+	}
+	return request, 0, nil
+}
+
+// 将以逗号分隔的参数值拆开
+func splitParameter(values []string) []string {
+	output := []string{}
+	for _, value := range values {
+		for _, field := range strings.Split(value, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				output = append(output, field)
+			}
+		}
+	}
+	return output
+}
+
+func parseJsonOptions(request *JsonRequest) (*jsonOptions, error) {
+	options := &jsonOptions{mode: request.Mode, stopWords: request.StopWords}
+	switch request.Mode {
+	case "":
+		options.mode = "normal"
+	case "normal", "search", "full":
+	default:
+		return nil, fmt.Errorf("invalid mode %q, must be normal, search or full", request.Mode)
+	}
+	switch request.Offsets {
+	case "", "byte":
+		options.unit = sego.ByteOffset
+	case "rune":
+		options.unit = sego.RuneOffset
+	case "utf16":
+		options.unit = sego.UTF16Offset
+	default:
+		return nil, fmt.Errorf("invalid offsets %q, must be byte, rune or utf16", request.Offsets)
+	}
+	if len(request.Pos) > 0 {
+		options.pos = toSet(request.Pos)
+	}
+	options.excludePos = toSet(request.ExcludePos)
+	if options.stopWords && stopWords == nil {
+		return nil, fmt.Errorf("stop words are not configured on this server")
+	}
+	return options, nil
+}
+
+func toSet(values []string) map[string]bool {
+	set := map[string]bool{}
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
+
+// 按选项对多段文本分词
+func segmentTexts(ctx context.Context,
+	seg *sego.Segmenter, texts []string, options *jsonOptions) ([]sego.JSONSegments, error) {
+	results := make([]sego.JSONSegments, len(texts))
+	for i := range results {
+		results[i].Unit = options.unit
+	}
+	if options.mode == "full" {
+		for i, text := range texts {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			segments, err := fullModeSegments(seg, []byte(text), options)
+			if err != nil {
+				return nil, err
+			}
+			results[i].Segments = segments
+		}
+		return results, nil
+	}
+
+	input := make([][]byte, len(texts))
+	for i, text := range texts {
+		input[i] = []byte(text)
+	}
+	output, err := seg.SegmentBatch(ctx, input, 0)
+	if err != nil {
+		return nil, err
+	}
+	for i, segments := range output {
+		if options.mode == "search" {
+			segments = sego.SearchSegments(segments, sego.SearchOptions{})
+		}
+		results[i].Segments = []sego.Segment{}
+		for _, segment := range segments {
+			if options.accept(segment.Token()) {
+				results[i].Segments = append(results[i].Segments, segment)
+			}
+		}
+	}
+	return results, nil
+}
+
+// 全模式分词：输出分词网格中的所有候选分词，按起始位置排序
+func fullModeSegments(seg *sego.Segmenter, text []byte, options *jsonOptions) ([]sego.Segment, error) {
+	limits := seg.Limits()
+	if limits.MaxTextBytes > 0 && len(text) > limits.MaxTextBytes {
+		return nil, sego.ErrTextTooLong
+	}
+	candidates := seg.Explain(text).Candidates
+	if limits.MaxSegments > 0 && len(candidates) > limits.MaxSegments {
+		return nil, sego.ErrTooManySegments
+	}
+
+	segments := []sego.Segment{}
+	for i := range candidates {
+		if options.accept(candidates[i].Token) {
+			segments = append(segments, candidates[i].Segment())
+		}
+	}
+	return segments, nil
+}
+
+// 分词是否通过词性和停用词的过滤
+func (options *jsonOptions) accept(token *sego.Token) bool {
+	if options.pos != nil && !options.pos[token.Pos()] {
+		return false
+	}
+	if options.excludePos[token.Pos()] {
+		return false
+	}
+	if options.stopWords && stopWords[strings.ToLower(token.Text())] {
+		return false
+	}
+	return true
+}
+
+// 以{"error":"..."}的格式输出错误
+func writeJsonError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// 载入停用词文件，每行一个停用词，英文按小写匹配
+func loadStopWords(file string) map[string]bool {
+	stopWordsFile, err := os.Open(file)
+	if err != nil {
+		log.Fatalf("无法载入停用词文件 \"%s\" \n", file)
+	}
+	defer stopWordsFile.Close()
+
+	words := map[string]bool{}
+	scanner := bufio.NewScanner(stopWordsFile)
+	for scanner.Scan() {
+		if word := strings.TrimSpace(scanner.Text()); word != "" {
+			words[strings.ToLower(word)] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatalf("读取停用词文件 \"%s\" 失败：%v\n", file, err)
+	}
+	return words
+}
+
+// 限制请求体的大小，超过-max_body_bytes时读取请求体返回错误
+func limitBody(w http.ResponseWriter, req *http.Request) io.Reader {
+	if *maxBodyBytes <= 0 {
+		return req.Body
+	}
+	return http.MaxBytesReader(w, req.Body, int64(*maxBodyBytes))
+}
+
+// 是否为limitBody返回的请求体过大的错误
+func isBodyTooLarge(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "http: request body too large")
+}
+
+// Elasticsearch _analyze接口的请求
+type AnalyzeRequest struct {
+	Analyzer string `json:"analyzer"`
+
+	// 字符串或字符串数组
+	Text json.RawMessage `json:"text"`
+
+	// Elasticsearch支持但这里不支持的参数，出现时返回错误而不是忽略，以免调用者
+	// 误以为它们生效了
+	Tokenizer  json.RawMessage `json:"tokenizer"`
+	Filter     json.RawMessage `json:"filter"`
+	CharFilter json.RawMessage `json:"char_filter"`
+	Normalizer json.RawMessage `json:"normalizer"`
+	Field      json.RawMessage `json:"field"`
+	Explain    bool            `json:"explain"`
+	Attributes json.RawMessage `json:"attributes"`
+}
+
+// 返回请求中第一个不支持的参数名，没有时返回空字符串
+func (request *AnalyzeRequest) unsupportedParameter() string {
+	for _, p := range []struct {
+		name  string
+		value json.RawMessage
+	}{
+		{"tokenizer", request.Tokenizer},
+		{"filter", request.Filter},
+		{"char_filter", request.CharFilter},
+		{"normalizer", request.Normalizer},
+		{"field", request.Field},
+		{"attributes", request.Attributes},
+	} {
+		if len(p.value) > 0 && string(p.value) != "null" {
+			return p.name
+		}
+	}
+	if request.Explain {
+		return "explain"
+	}
+	return ""
+}
+
+// Elasticsearch _analyze接口的输出
+type AnalyzeResponse struct {
+	Tokens []AnalyzeToken `json:"tokens"`
+}
+
+type AnalyzeToken struct {
+	Token       string `json:"token"`
+	StartOffset int    `json:"start_offset"`
+	EndOffset   int    `json:"end_offset"`
+	Type        string `json:"type"`
+	Position    int    `json:"position"`
+}
+
+const (
+	// 和Elasticsearch相同，text为数组时相邻两段文本之间的位置和偏移量间隔
+	positionIncrementGap = 100
+	offsetGap            = 1
+)
+
+func AnalyzeServer(w http.ResponseWriter, req *http.Request) {
+	// 得到分析器和要分析的文本
+	request := AnalyzeRequest{Analyzer: req.URL.Query().Get("analyzer")}
+	texts := req.URL.Query()["text"]
+	if req.Body != nil && req.Body != http.NoBody {
+		// 和Elasticsearch相同，GET请求也可以带JSON请求体
+		decoder := json.NewDecoder(limitBody(w, req))
+		decoder.DisallowUnknownFields()
+		err := decoder.Decode(&request)
+		if err == nil && decoder.More() {
+			err = fmt.Errorf("unexpected data after the JSON value")
+		}
+		if isBodyTooLarge(err) {
+			writeAnalyzeError(w, http.StatusRequestEntityTooLarge,
+				"illegal_argument_exception", "request body is too large")
+			return
+		} else if err != nil && err != io.EOF {
+			writeAnalyzeError(w, http.StatusBadRequest, "parse_exception", "request body is invalid: "+err.Error())
+			return
+		}
+		if len(request.Text) > 0 {
+			var text string
+			if err := json.Unmarshal(request.Text, &text); err == nil {
+				texts = []string{text}
+			} else if err := json.Unmarshal(request.Text, &texts); err != nil {
+				writeAnalyzeError(w, http.StatusBadRequest,
+					"parse_exception", "[text] must be a string or an array of strings")
+				return
+			}
+		}
+	}
+	if name := request.unsupportedParameter(); name != "" {
+		writeAnalyzeError(w, http.StatusBadRequest, "illegal_argument_exception",
+			fmt.Sprintf("[%s] is not supported, only [analyzer] and [text] are", name))
+		return
+	}
+	if len(texts) == 0 {
+		writeAnalyzeError(w, http.StatusBadRequest, "action_request_validation_exception", "text is missing")
+		return
+	}
+
+	searchMode := false
+	switch request.Analyzer {
+	case "", "sego":
+	case "sego_search":
+		searchMode = true
+	default:
+		writeAnalyzeError(w, http.StatusBadRequest, "illegal_argument_exception",
+			fmt.Sprintf("failed to find global analyzer [%s]", request.Analyzer))
+		return
+	}
+
+	// 分析
+	seg := currentSegmenter()
+	if seg == nil {
+		writeAnalyzeError(w, http.StatusServiceUnavailable, "status_exception", "dictionary is loading")
+		return
+	}
+	response := AnalyzeResponse{Tokens: []AnalyzeToken{}}
+	position, offset := -1, 0
+	for i, text := range texts {
+		segments, err := seg.SegmentContext(req.Context(), []byte(text))
+		if err == sego.ErrTextTooLong || err == sego.ErrTooManySegments {
+			writeAnalyzeError(w, http.StatusRequestEntityTooLarge, "illegal_argument_exception", err.Error())
+			return
+		} else if err != nil {
+			writeAnalyzeError(w, http.StatusServiceUnavailable, "task_cancelled_exception", err.Error())
+			return
+		}
+		metrics.addSegmentedBytes(len(text))
+		if i > 0 {
+			position += positionIncrementGap
+		}
+		position = appendAnalyzeTokens(&response, segments, searchMode, position, offset)
+		if len(segments) > 0 {
+			offset += segments[len(segments)-1].UTF16End()
+		}
+		offset += offsetGap
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(&response)
+}
+
+// 将sego.IndexTerms返回的词加入输出。position为上一个词的位置，返回最后一个词
+// 的位置。
+func appendAnalyzeTokens(response *AnalyzeResponse,
+	segments []sego.Segment, searchMode bool, position, offset int) int {
+	last := position
+	for _, term := range sego.IndexTerms(segments, searchMode) {
+		tokenType := term.Token().Pos()
+		if tokenType == "" {
+			tokenType = "word"
+		}
+		last = position + 1 + term.Position
+		response.Tokens = append(response.Tokens, AnalyzeToken{
+			Token:       term.Token().Text(),
+			StartOffset: offset + term.UTF16Start(),
+			EndOffset:   offset + term.UTF16End(),
+			Type:        tokenType,
+			Position:    last,
+		})
+	}
+	return last
+}
+
+// 以Elasticsearch的格式输出错误
+func writeAnalyzeError(w http.ResponseWriter, status int, errorType, reason string) {
+	cause := map[string]interface{}{"type": errorType, "reason": reason}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"root_cause": []interface{}{cause},
+			"type":       errorType,
+			"reason":     reason,
+		},
+		"status": status,
+	})
+}
+
+// 加入的词的最小频率，和载入词典时相同，频率更小的词被忽略
+const minWordFrequency = 2
+
+// 用户词典中的一个词，即管理接口加入的词。用户词典文件中频率为0的词表示从
+// 词典中删除该词。
+type AdminWord struct {
+	Text      string `json:"text"`
+	Frequency int    `json:"frequency"`
+	Pos       string `json:"pos"`
+}
+
+// 词典的统计信息
+type DictionaryStats struct {
+	NumTokens      int   `json:"num_tokens"`
+	TotalFrequency int64 `json:"total_frequency"`
+	MaxTokenLength int   `json:"max_token_length"`
+	UserWords      int   `json:"user_words"`
+	RemovedWords   int   `json:"removed_words"`
+}
+
+var (
+	// 用户词典中加入和删除的词，键为小写的分词文本
+	userWords    = map[string]AdminWord{}
+	removedWords = map[string]bool{}
+
+	// 串行化词典的修改和重新载入
+	adminLock sync.Mutex
+)
+
+func currentSegmenter() *sego.Segmenter {
+	segmenterLock.RLock()
+	defer segmenterLock.RUnlock()
+	return segmenter
+}
+
+func setSegmenter(seg *sego.Segmenter) {
+	segmenterLock.Lock()
+	defer segmenterLock.Unlock()
+	segmenter = seg
+}
+
+// 词典管理接口，需要在Authorization头中提供"Bearer 管理密钥"
+func AdminServer(w http.ResponseWriter, req *http.Request) {
+	if !authorizeAdmin(w, req) {
+		return
+	}
+
+	adminLock.Lock()
+	defer adminLock.Unlock()
+
+	var err error
+	status := http.StatusBadRequest
+	switch {
+	case req.URL.Path == "/admin/dictionary" && req.Method == http.MethodGet:
+	case req.URL.Path == "/admin/words" && req.Method == http.MethodGet:
+		lookupWord(w, req)
+		return
+	case req.URL.Path == "/admin/words" && req.Method == http.MethodPost:
+		status, err = addWords(w, req)
+	case req.URL.Path == "/admin/words" && req.Method == http.MethodDelete:
+		status, err = removeWords(req)
+	case req.URL.Path == "/admin/reload" && req.Method == http.MethodPost:
+		status, err = http.StatusInternalServerError, reloadDictionary()
+	case req.URL.Path == "/admin/dictionary" || req.URL.Path == "/admin/words" ||
+		req.URL.Path == "/admin/reload":
+		writeJsonError(w, http.StatusMethodNotAllowed, "method "+req.Method+" is not allowed")
+		return
+	default:
+		writeJsonError(w, http.StatusNotFound, "no such admin endpoint "+req.URL.Path)
+		return
+	}
+	if err != nil {
+		writeJsonError(w, status, err.Error())
+		return
+	}
+
+	// 输出修改后的词典统计信息
+	dictionary := currentSegmenter().Dictionary()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(&DictionaryStats{
+		NumTokens:      dictionary.NumTokens(),
+		TotalFrequency: dictionary.TotalFrequency(),
+		MaxTokenLength: dictionary.MaxTokenLength(),
+		UserWords:      len(userWords),
+		RemovedWords:   len(removedWords),
+	})
+}
+
+// 检查管理密钥，没有设置管理密钥时管理接口不可用
+func authorizeAdmin(w http.ResponseWriter, req *http.Request) bool {
+	if *adminToken == "" {
+		writeJsonError(w, http.StatusForbidden, "admin endpoints are disabled, set -admin_token to enable them")
+		return false
+	}
+	authorization := req.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, "Bearer ") ||
+		subtle.ConstantTimeCompare([]byte(authorization[len("Bearer "):]), []byte(*adminToken)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="sego"`)
+		writeJsonError(w, http.StatusUnauthorized, "invalid admin token")
+		return false
+	}
+	return true
+}
+
+// 在当前词典中查找text参数指定的词
+func lookupWord(w http.ResponseWriter, req *http.Request) {
+	text := req.URL.Query().Get("text")
+	if text == "" {
+		writeJsonError(w, http.StatusBadRequest, "text is missing")
+		return
+	}
+	token := currentSegmenter().Dictionary().Lookup([]byte(text))
+	if token == nil {
+		writeJsonError(w, http.StatusNotFound, fmt.Sprintf("%q is not in the dictionary", text))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(&AdminWord{Text: token.Text(), Frequency: token.Frequency(), Pos: token.Pos()})
+}
+
+// 加入词，请求体为AdminWord或AdminWord数组，或者是text、frequency和pos表单参数。
+// 出错时同时返回HTTP状态码。
+func addWords(w http.ResponseWriter, req *http.Request) (int, error) {
+	words := []AdminWord{}
+	if strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		body, err := io.ReadAll(limitBody(w, req))
+		if isBodyTooLarge(err) {
+			return http.StatusRequestEntityTooLarge, fmt.Errorf("request body is too large")
+		} else if err != nil {
+			return http.StatusBadRequest, err
+		}
+		body = bytes.TrimSpace(body)
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.DisallowUnknownFields()
+		if len(body) > 0 && body[0] == '[' {
+			err = decoder.Decode(&words)
+		} else {
+			words = append(words, AdminWord{})
+			err = decoder.Decode(&words[0])
+		}
+		if err == nil && decoder.More() {
+			err = fmt.Errorf("unexpected data after the JSON value")
+		}
+		if err != nil {
+			return http.StatusBadRequest, fmt.Errorf("request body is invalid: %v", err)
+		}
+	} else {
+		if req.Body != nil && *maxBodyBytes > 0 {
+			req.Body = http.MaxBytesReader(w, req.Body, int64(*maxBodyBytes))
+		}
+		if err := req.ParseForm(); isBodyTooLarge(err) {
+			return http.StatusRequestEntityTooLarge, fmt.Errorf("request body is too large")
+		}
+		frequency, err := strconv.Atoi(req.FormValue("frequency"))
+		if err != nil {
+			return http.StatusBadRequest, fmt.Errorf("invalid frequency %q", req.FormValue("frequency"))
+		}
+		words = append(words, AdminWord{
+			Text: req.FormValue("text"), Frequency: frequency, Pos: req.FormValue("pos")})
+	}
+	if len(words) == 0 {
+		return http.StatusBadRequest, fmt.Errorf("no words to add")
+	}
+
+	// 词典文件以空白分隔各列，因此分词和词性中不能有空白
+	for _, word := range words {
+		if word.Text == "" || strings.IndexFunc(word.Text, unicode.IsSpace) >= 0 {
+			return http.StatusBadRequest, fmt.Errorf("invalid text %q", word.Text)
+		}
+		if strings.IndexFunc(word.Pos, unicode.IsSpace) >= 0 {
+			return http.StatusBadRequest, fmt.Errorf("invalid pos %q", word.Pos)
+		}
+		if word.Frequency < minWordFrequency {
+			return http.StatusBadRequest,
+				fmt.Errorf("frequency of %q must be at least %d", word.Text, minWordFrequency)
+		}
+	}
+	return http.StatusInternalServerError,
+		updateDictionary(func(added map[string]AdminWord, removed map[string]bool) {
+			for _, word := range words {
+				key := strings.ToLower(word.Text)
+				added[key] = word
+				delete(removed, key)
+			}
+		})
+}
+
+// 删除text参数指定的词，可以有多个text参数
+func removeWords(req *http.Request) (int, error) {
+	texts := req.URL.Query()["text"]
+	if len(texts) == 0 {
+		return http.StatusBadRequest, fmt.Errorf("text is missing")
+	}
+	dictionary := currentSegmenter().Dictionary()
+	for _, text := range texts {
+		if dictionary.Lookup([]byte(text)) == nil {
+			return http.StatusNotFound, fmt.Errorf("%q is not in the dictionary", text)
+		}
+	}
+	return http.StatusInternalServerError,
+		updateDictionary(func(added map[string]AdminWord, removed map[string]bool) {
+			for _, text := range texts {
+				key := strings.ToLower(text)
+				delete(added, key)
+				removed[key] = true
+			}
+		})
+}
+
+// 修改用户词典的副本并用其重建分词器，成功后保存用户词典并替换当前的分词器。
+// 调用者需持有adminLock。
+func updateDictionary(change func(added map[string]AdminWord, removed map[string]bool)) error {
+	added := make(map[string]AdminWord, len(userWords))
+	for key, word := range userWords {
+		added[key] = word
+	}
+	removed := make(map[string]bool, len(removedWords))
+	for key := range removedWords {
+		removed[key] = true
+	}
+	change(added, removed)
+
+	seg, err := buildSegmenter(added, removed)
+	if err != nil {
+		return err
+	}
+	if *userDict != "" {
+		if err := saveUserDictionary(*userDict, added, removed); err != nil {
+			return err
+		}
+	}
+	userWords, removedWords = added, removed
+	setSegmenter(seg)
+	return nil
+}
+
+// 从文件重新载入词典和用户词典。调用者需持有adminLock。
+func reloadDictionary() error {
+	added, removed := userWords, removedWords
+	if *userDict != "" {
+		var err error
+		added, removed, err = loadUserDictionary(*userDict)
+		if err != nil {
+			return err
+		}
+	}
+	seg, err := buildSegmenter(added, removed)
+	if err != nil {
+		return err
+	}
+	userWords, removedWords = added, removed
+	setSegmenter(seg)
+	return nil
+}
+
+// 创建新的分词器，用户词典中加入的词优先于词典文件，删除的词从词典文件中去掉
+func buildSegmenter(added map[string]AdminWord, removed map[string]bool) (seg *sego.Segmenter, err error) {
+	defer func(start time.Time) {
+		metrics.recordReload(start, err == nil)
+	}(time.Now())
+
+	var user bytes.Buffer
+	writeUserDictionary(&user, added, nil)
+	readers := []io.Reader{&user}
+	for _, file := range strings.Split(*dict, ",") {
+		log.Printf("载入sego词典 %s", file)
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		readers = append(readers, bytes.NewReader(filterRemovedWords(content, removed)))
+	}
+
+	seg = new(sego.Segmenter)
+	seg.LoadDictionaryFromReaders(readers...)
+	seg.SetLimits(sego.Limits{MaxTextBytes: *maxTextBytes, MaxSegments: *maxSegments})
+	log.Println("sego词典载入完毕")
+	return seg, nil
+}
+
+// 去掉词典文件内容中被删除的词所在的行
+func filterRemovedWords(content []byte, removed map[string]bool) []byte {
+	if len(removed) == 0 {
+		return content
+	}
+	output := make([]byte, 0, len(content))
+	for len(content) > 0 {
+		line := content
+		if newline := bytes.IndexByte(content, '\n'); newline >= 0 {
+			line = content[:newline+1]
+		}
+		content = content[len(line):]
+		if fields := bytes.Fields(line); len(fields) > 0 && removed[strings.ToLower(string(fields[0]))] {
+			continue
+		}
+		output = append(output, line...)
+	}
+	return output
+}
+
+// 读入用户词典文件，文件不存在时用户词典为空
+func loadUserDictionary(file string) (map[string]AdminWord, map[string]bool, error) {
+	added, removed := map[string]AdminWord{}, map[string]bool{}
+	content, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return added, removed, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		frequency, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		key := strings.ToLower(fields[0])
+		if frequency == 0 {
+			removed[key] = true
+			continue
+		}
+		word := AdminWord{Text: fields[0], Frequency: frequency}
+		if len(fields) > 2 {
+			word.Pos = fields[2]
+		}
+		added[key] = word
+	}
+	return added, removed, nil
+}
+
+// 以词典文件的格式输出用户词典，删除的词频率为0，按文本排序
+func writeUserDictionary(w io.Writer, added map[string]AdminWord, removed map[string]bool) {
+	keys := make([]string, 0, len(added))
+	for key := range added {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		word := added[key]
+		if word.Pos == "" {
+			fmt.Fprintf(w, "%s %d\n", word.Text, word.Frequency)
+		} else {
+			fmt.Fprintf(w, "%s %d %s\n", word.Text, word.Frequency, word.Pos)
+		}
+	}
+
+	keys = keys[:0]
+	for key := range removed {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s 0\n", key)
+	}
+}
+
+// 保存用户词典，先写入临时文件再改名，以免保存失败时破坏原有文件
+func saveUserDictionary(file string, added map[string]AdminWord, removed map[string]bool) error {
+	var content bytes.Buffer
+	writeUserDictionary(&content, added, removed)
+	if err := os.WriteFile(file+".tmp", content.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(file+".tmp", file)
+}
+
+// 请求耗时直方图的上界（秒）
+var latencyBuckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+
+// 服务器的监控指标，由/metrics以Prometheus文本格式输出
+type serverMetrics struct {
+	lock sync.Mutex
+
+	// 按处理器和状态码统计的请求数
+	requests map[requestKey]uint64
+
+	// 按处理器统计的请求耗时
+	latencies map[string]*histogram
+
+	// 分词的文本总字节数
+	segmentedBytes uint64
+
+	// 词典的载入次数、最近一次载入的时间、耗时和结果
+	reloads          map[bool]uint64
+	lastReload       time.Time
+	lastReloadTime   time.Duration
+	lastReloadFailed bool
+}
+
+type requestKey struct {
+	handler string
+	code    int
+}
+
+type histogram struct {
+	// 每个上界内的请求数，不累计
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+var metrics = serverMetrics{
+	requests:  map[requestKey]uint64{},
+	latencies: map[string]*histogram{},
+	reloads:   map[bool]uint64{},
+}
+
+func (m *serverMetrics) recordRequest(handler string, code int, duration time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.requests[requestKey{handler, code}]++
+	h, ok := m.latencies[handler]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(latencyBuckets))}
+		m.latencies[handler] = h
+	}
+	seconds := duration.Seconds()
+	for i, bound := range latencyBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+func (m *serverMetrics) addSegmentedBytes(n int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.segmentedBytes += uint64(n)
+}
+
+func (m *serverMetrics) recordReload(start time.Time, success bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.reloads[success]++
+	m.lastReload = start
+	m.lastReloadTime = time.Since(start)
+	m.lastReloadFailed = !success
+}
+
+// 以Prometheus文本格式输出所有指标
+func (m *serverMetrics) writeTo(w io.Writer) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	fmt.Fprintln(w, "# HELP sego_http_requests_total Number of HTTP requests by handler and status code.")
+	fmt.Fprintln(w, "# TYPE sego_http_requests_total counter")
+	keys := make([]requestKey, 0, len(m.requests))
+	for key := range m.requests {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].handler != keys[j].handler {
+			return keys[i].handler < keys[j].handler
+		}
+		return keys[i].code < keys[j].code
+	})
+	for _, key := range keys {
+		fmt.Fprintf(w, "sego_http_requests_total{handler=%q,code=\"%d\"} %d\n",
+			key.handler, key.code, m.requests[key])
+	}
+
+	fmt.Fprintln(w, "# HELP sego_http_request_duration_seconds Latency of HTTP requests by handler.")
+	fmt.Fprintln(w, "# TYPE sego_http_request_duration_seconds histogram")
+	handlers := make([]string, 0, len(m.latencies))
+	for handler := range m.latencies {
+		handlers = append(handlers, handler)
+	}
+	sort.Strings(handlers)
+	for _, handler := range handlers {
+		h := m.latencies[handler]
+		cumulative := uint64(0)
+		for i, bound := range latencyBuckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(w, "sego_http_request_duration_seconds_bucket{handler=%q,le=\"%s\"} %d\n",
+				handler, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(w, "sego_http_request_duration_seconds_bucket{handler=%q,le=\"+Inf\"} %d\n", handler, h.count)
+		fmt.Fprintf(w, "sego_http_request_duration_seconds_sum{handler=%q} %g\n", handler, h.sum)
+		fmt.Fprintf(w, "sego_http_request_duration_seconds_count{handler=%q} %d\n", handler, h.count)
+	}
+
+	fmt.Fprintln(w, "# HELP sego_segmented_bytes_total Number of text bytes segmented.")
+	fmt.Fprintln(w, "# TYPE sego_segmented_bytes_total counter")
+	fmt.Fprintf(w, "sego_segmented_bytes_total %d\n", m.segmentedBytes)
+
+	numTokens, totalFrequency := 0, int64(0)
+	if seg := currentSegmenter(); seg != nil {
+		numTokens = seg.Dictionary().NumTokens()
+		totalFrequency = seg.Dictionary().TotalFrequency()
+	}
+	fmt.Fprintln(w, "# HELP sego_dictionary_tokens Number of tokens in the dictionary.")
+	fmt.Fprintln(w, "# TYPE sego_dictionary_tokens gauge")
+	fmt.Fprintf(w, "sego_dictionary_tokens %d\n", numTokens)
+	fmt.Fprintln(w, "# HELP sego_dictionary_total_frequency Sum of token frequencies in the dictionary.")
+	fmt.Fprintln(w, "# TYPE sego_dictionary_total_frequency gauge")
+	fmt.Fprintf(w, "sego_dictionary_total_frequency %d\n", totalFrequency)
+
+	fmt.Fprintln(w, "# HELP sego_dictionary_reloads_total Number of dictionary loads by result.")
+	fmt.Fprintln(w, "# TYPE sego_dictionary_reloads_total counter")
+	fmt.Fprintf(w, "sego_dictionary_reloads_total{result=\"success\"} %d\n", m.reloads[true])
+	fmt.Fprintf(w, "sego_dictionary_reloads_total{result=\"failure\"} %d\n", m.reloads[false])
+	if !m.lastReload.IsZero() {
+		success := 1
+		if m.lastReloadFailed {
+			success = 0
+		}
+		fmt.Fprintln(w, "# HELP sego_dictionary_last_reload_success Whether the last dictionary load succeeded.")
+		fmt.Fprintln(w, "# TYPE sego_dictionary_last_reload_success gauge")
+		fmt.Fprintf(w, "sego_dictionary_last_reload_success %d\n", success)
+		fmt.Fprintln(w, "# HELP sego_dictionary_last_reload_timestamp_seconds Start time of the last dictionary load.")
+		fmt.Fprintln(w, "# TYPE sego_dictionary_last_reload_timestamp_seconds gauge")
+		fmt.Fprintf(w, "sego_dictionary_last_reload_timestamp_seconds %d\n", m.lastReload.Unix())
+		fmt.Fprintln(w, "# HELP sego_dictionary_last_reload_duration_seconds Duration of the last dictionary load.")
+		fmt.Fprintln(w, "# TYPE sego_dictionary_last_reload_duration_seconds gauge")
+		fmt.Fprintf(w, "sego_dictionary_last_reload_duration_seconds %g\n", m.lastReloadTime.Seconds())
+	}
+}
+
+func MetricsServer(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	metrics.writeTo(w)
+}
+
+// 服务器进程存活即健康
+func HealthServer(w http.ResponseWriter, req *http.Request) {
+	io.WriteString(w, "ok\n")
+}
+
+// 词典载入完毕后才可以处理分词请求
+func ReadyServer(w http.ResponseWriter, req *http.Request) {
+	if currentSegmenter() == nil {
+		http.Error(w, "dictionary is loading", http.StatusServiceUnavailable)
+		return
+	}
+	io.WriteString(w, "ok\n")
+}
+
+// 访问日志的一行，JSON格式
+type accessLogEntry struct {
+	Time       string  `json:"time"`
+	Handler    string  `json:"handler"`
+	Method     string  `json:"method"`
+	Path       string  `json:"path"`
+	Status     int     `json:"status"`
+	Bytes      int     `json:"bytes"`
+	Duration   float64 `json:"duration_seconds"`
+	RemoteAddr string  `json:"remote_addr"`
+	UserAgent  string  `json:"user_agent,omitempty"`
+}
+
+var (
+	// 访问日志，为nil时不输出
+	accessLog     *json.Encoder
+	accessLogLock sync.Mutex
+)
+
+// 记录响应的状态码和字节数
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(data)
+	r.bytes += n
+	return n, err
+}
+
+// 包装处理器，统计请求数和耗时并输出访问日志，handler为指标和日志中的处理器名
+func instrument(handler string, serve http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		recorder := &responseRecorder{ResponseWriter: w}
+		serve(recorder, req)
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
+		}
+		duration := time.Since(start)
+		metrics.recordRequest(handler, recorder.status, duration)
+
+		if accessLog == nil {
+			return
+		}
+		accessLogLock.Lock()
+		defer accessLogLock.Unlock()
+		accessLog.Encode(&accessLogEntry{
+			Time:       start.Format(time.RFC3339Nano),
+			Handler:    handler,
+			Method:     req.Method,
+			Path:       req.URL.Path,
+			Status:     recorder.status,
+			Bytes:      recorder.bytes,
+			Duration:   duration.Seconds(),
+			RemoteAddr: req.RemoteAddr,
+			UserAgent:  req.UserAgent(),
+		})
+	}
+}
+
 func main() {
 	flag.Parse()
 
@@ -78,10 +1247,52 @@ func main() {
 	runtime.GOMAXPROCS(runtime.NumCPU())
 
 	// 初始化分词器
-	segmenter.LoadDictionary(*dict)
+	if *adminToken == "" {
+		*adminToken = os.Getenv("SEGO_ADMIN_TOKEN")
+	}
+	if *stopWordsFile != "" {
+		stopWords = loadStopWords(*stopWordsFile)
+	}
+	switch *accessLogFile {
+	case "":
+	case "-":
+		accessLog = json.NewEncoder(os.Stdout)
+	default:
+		file, err := os.OpenFile(*accessLogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			log.Fatalf("无法打开访问日志文件 \"%s\" \n", *accessLogFile)
+		}
+		accessLog = json.NewEncoder(file)
+	}
+	if accessLog != nil {
+		accessLog.SetEscapeHTML(false)
+	}
+
+	// 在后台载入词典，载入完毕之前/readyz返回503，管理接口等待载入完毕
+	adminLock.Lock()
+	go func() {
+		defer adminLock.Unlock()
+		if err := reloadDictionary(); err != nil {
+			log.Fatalf("无法载入词典：%v\n", err)
+		}
+	}()
 
-	http.HandleFunc("/json", JsonRpcServer)
-	http.Handle("/", http.FileServer(http.Dir(*staticFolder)))
+	http.HandleFunc("/json", instrument("json", JsonRpcServer))
+	analyzeServer := instrument("analyze", AnalyzeServer)
+	http.HandleFunc("/_analyze", analyzeServer)
+	http.HandleFunc("/admin/", instrument("admin", AdminServer))
+	http.HandleFunc("/metrics", MetricsServer)
+	http.HandleFunc("/healthz", HealthServer)
+	http.HandleFunc("/readyz", ReadyServer)
+	fileServer := instrument("static", http.FileServer(http.Dir(*staticFolder)).ServeHTTP)
+	http.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		// Elasticsearch也允许在索引名之后访问_analyze
+		if strings.HasSuffix(req.URL.Path, "/_analyze") {
+			analyzeServer(w, req)
+			return
+		}
+		fileServer(w, req)
+	})
 	log.Print("服务器启动")
 	http.ListenAndServe(fmt.Sprintf("%s:%d", *host, *port), nil)
 }
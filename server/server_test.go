@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	*dict = "../testdata/test_dict4.txt"
+	if err := reloadDictionary(); err != nil {
+		log.Fatal(err)
+	}
+	os.Exit(m.Run())
+}
+
+func expect(t *testing.T, expect string, actual interface{}) {
+	t.Helper()
+	actualString := fmt.Sprint(actual)
+	if expect != actualString {
+		t.Errorf("期待值=\"%s\", 实际=\"%s\"", expect, actualString)
+	}
+}
+
+// 用handler处理请求，返回状态码和去掉末尾换行的响应
+func serve(handler http.HandlerFunc, method, target, body string, header ...string) (int, string) {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, target, reader)
+	if strings.HasPrefix(body, "{") || strings.HasPrefix(body, "[") {
+		req.Header.Set("Content-Type", "application/json")
+	} else if body != "" {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	handler(w, req)
+	return w.Code, strings.TrimSuffix(w.Body.String(), "\n")
+}
+
+func TestAnalyzeServer(t *testing.T) {
+	code, body := serve(AnalyzeServer, "GET", "/_analyze?text=%E4%B8%AD%E5%8D%8E", "")
+	expect(t, "200", code)
+	expect(t, `{"tokens":[{"token":"中华","start_offset":0,"end_offset":2,"type":"nz","position":0}]}`, body)
+
+	// GET请求也可以带JSON请求体，text为数组时位置和偏移量留出间隔
+	code, body = serve(AnalyzeServer, "GET", "/_analyze",
+		`{"analyzer":"sego_search","text":["人民共和国","国"]}`)
+	expect(t, "200", code)
+	expect(t, `{"tokens":[`+
+		`{"token":"人民","start_offset":0,"end_offset":2,"type":"n","position":0},`+
+		`{"token":"共和","start_offset":2,"end_offset":4,"type":"nz","position":0},`+
+		`{"token":"共和国","start_offset":2,"end_offset":5,"type":"ns","position":0},`+
+		`{"token":"人民共和国","start_offset":0,"end_offset":5,"type":"nt","position":0},`+
+		`{"token":"国","start_offset":6,"end_offset":7,"type":"n","position":101}]}`, body)
+
+	// 错误
+	code, body = serve(AnalyzeServer, "POST", "/_analyze", `{"text":`)
+	expect(t, "400", code)
+	expect(t, "true", strings.Contains(body, `"type":"parse_exception"`))
+	code, body = serve(AnalyzeServer, "POST", "/_analyze", `{"text":1}`)
+	expect(t, "400", code)
+	expect(t, "true", strings.Contains(body, `"reason":"[text] must be a string or an array of strings"`))
+	code, body = serve(AnalyzeServer, "GET", "/_analyze", "")
+	expect(t, "400", code)
+	expect(t, `{"error":{"reason":"text is missing","root_cause":[{"reason":"text is missing",`+
+		`"type":"action_request_validation_exception"}],"type":"action_request_validation_exception"},"status":400}`, body)
+	code, body = serve(AnalyzeServer, "POST", "/_analyze", `{"text":"a"} {"text":"b"}`)
+	expect(t, "400", code)
+	expect(t, "true", strings.Contains(body, `"reason":"request body is invalid: unexpected data after the JSON value"`))
+	code, body = serve(AnalyzeServer, "POST", "/_analyze", `{"text":"a","analyser":"sego"}`)
+	expect(t, "400", code)
+	expect(t, "true", strings.Contains(body, `"type":"parse_exception"`))
+	for _, parameter := range []string{`"tokenizer":"standard"`, `"filter":["lowercase"]`,
+		`"field":"title"`, `"explain":true`} {
+		code, body = serve(AnalyzeServer, "POST", "/_analyze", `{"text":"a",`+parameter+`}`)
+		expect(t, "400", code)
+		expect(t, "true", strings.Contains(body, `"type":"illegal_argument_exception"`))
+		expect(t, "true", strings.Contains(body, "is not supported, only [analyzer] and [text] are"))
+	}
+	code, _ = serve(AnalyzeServer, "POST", "/_analyze", `{"text":"a","explain":false}`)
+	expect(t, "200", code)
+	code, body = serve(AnalyzeServer, "GET", "/_analyze?analyzer=standard&text=a", "")
+	expect(t, "400", code)
+	expect(t, "true", strings.Contains(body, `"reason":"failed to find global analyzer [standard]"`))
+
+	*maxTextBytes = 3
+	reloadDictionary()
+	code, body = serve(AnalyzeServer, "GET", "/_analyze?text=%E4%B8%AD%E5%8D%8E", "")
+	*maxTextBytes = 1 << 20
+	reloadDictionary()
+	expect(t, "413", code)
+	expect(t, "true", strings.Contains(body, `"status":413`))
+
+	*maxBodyBytes = 10
+	code, body = serve(AnalyzeServer, "POST", "/_analyze", `{"text":"中华人民共和国"}`)
+	*maxBodyBytes = 10 << 20
+	expect(t, "413", code)
+	expect(t, "true", strings.Contains(body, `"reason":"request body is too large"`))
+}
+
+func TestJsonRpcServer(t *testing.T) {
+	code, body := serve(JsonRpcServer, "GET", "/json?text=%E4%B8%AD%E5%8D%8E%E5%9B%BD", "")
+	expect(t, "200", code)
+	expect(t, `{"segments":[{"text":"中华","pos":"nz","start":0,"end":6,"frequency":100},`+
+		`{"text":"国","pos":"n","start":6,"end":9,"frequency":100}]}`, body)
+
+	// 搜索模式、词性过滤和按字符计数的位置
+	code, body = serve(JsonRpcServer, "POST", "/json",
+		`{"text":"人民共和国","mode":"search","offsets":"rune","exclude_pos":["nz","nt"]}`)
+	expect(t, "200", code)
+	expect(t, `{"segments":[{"text":"人民","pos":"n","start":0,"end":2,"frequency":100},`+
+		`{"text":"共和国","pos":"ns","start":2,"end":5,"frequency":100}]}`, body)
+	code, body = serve(JsonRpcServer, "POST", "/json", "text=%E4%B8%AD%E5%8D%8E%E5%9B%BD&mode=full&pos=n")
+	expect(t, "200", code)
+	expect(t, `{"segments":[{"text":"国","pos":"n","start":6,"end":9,"frequency":100}]}`, body)
+
+	// 停用词
+	stopWords = map[string]bool{"国": true}
+	code, body = serve(JsonRpcServer, "GET", "/json?text=%E4%B8%AD%E5%8D%8E%E5%9B%BD&stop_words=true", "")
+	stopWords = nil
+	expect(t, "200", code)
+	expect(t, `{"segments":[{"text":"中华","pos":"nz","start":0,"end":6,"frequency":100}]}`, body)
+
+	// 批量分词
+	code, body = serve(JsonRpcServer, "POST", "/json", `["中华","国"]`)
+	expect(t, "200", code)
+	expect(t, `{"results":[{"segments":[{"text":"中华","pos":"nz","start":0,"end":6,"frequency":100}]},`+
+		`{"segments":[{"text":"国","pos":"n","start":0,"end":3,"frequency":100}]}]}`, body)
+
+	// 错误
+	for _, c := range []struct{ method, target, body, status, error string }{
+		{"DELETE", "/json?text=a", "", "405", `{"error":"method DELETE is not allowed"}`},
+		{"GET", "/json", "", "400", `{"error":"text is missing"}`},
+		{"POST", "/json", `{"text":""}`, "400", `{"error":"text is missing"}`},
+		{"POST", "/json", `{"texts":[]}`, "400", `{"error":"texts is empty"}`},
+		{"POST", "/json", `{"text":"a","texts":["b"]}`, "400", `{"error":"text and texts can not be used together"}`},
+		{"POST", "/json", `{"text":"a","mdoe":"search"}`, "400",
+			`{"error":"request body is invalid: json: unknown field \"mdoe\""}`},
+		{"POST", "/json", `{"text":"a"} {}`, "400", `{"error":"request body is invalid: unexpected data after the JSON value"}`},
+		{"GET", "/json?text=a&mode=fast", "", "400", `{"error":"invalid mode \"fast\", must be normal, search or full"}`},
+		{"GET", "/json?text=a&offsets=char", "", "400",
+			`{"error":"invalid offsets \"char\", must be byte, rune or utf16"}`},
+		{"GET", "/json?text=a&stop_words=true", "", "400", `{"error":"stop words are not configured on this server"}`},
+	} {
+		code, body = serve(JsonRpcServer, c.method, c.target, c.body)
+		expect(t, c.status, code)
+		expect(t, c.error, body)
+	}
+
+	*maxBodyBytes = 10
+	code, body = serve(JsonRpcServer, "POST", "/json", `{"text":"中华人民共和国"}`)
+	expect(t, "413", code)
+	expect(t, `{"error":"request body is too large"}`, body)
+	code, body = serve(JsonRpcServer, "POST", "/json", "text=%E4%B8%AD%E5%8D%8E")
+	*maxBodyBytes = 10 << 20
+	expect(t, "413", code)
+	expect(t, `{"error":"request body is too large"}`, body)
+}
+
+func TestAdminServer(t *testing.T) {
+	code, _ := serve(AdminServer, "GET", "/admin/dictionary", "")
+	expect(t, "403", code)
+
+	*adminToken = "secret"
+	*userDict = filepath.Join(t.TempDir(), "user_dict.txt")
+	defer func() {
+		*adminToken, *userDict = "", ""
+		userWords, removedWords = map[string]AdminWord{}, map[string]bool{}
+		reloadDictionary()
+	}()
+	auth := []string{"Authorization", "Bearer secret"}
+
+	// 密钥错误或者没有Bearer前缀
+	for _, authorization := range []string{"", "secret", "Bearer wrong", "Basic secret"} {
+		code, body := serve(AdminServer, "GET", "/admin/dictionary", "", "Authorization", authorization)
+		expect(t, "401", code)
+		expect(t, `{"error":"invalid admin token"}`, body)
+	}
+
+	code, body := serve(AdminServer, "GET", "/admin/dictionary", "", auth...)
+	expect(t, "200", code)
+	expect(t, `{"num_tokens":7,"total_frequency":600,"max_token_length":7,"user_words":0,"removed_words":0}`, body)
+
+	// 加入、查找和删除
+	code, body = serve(AdminServer, "POST", "/admin/words",
+		`[{"text":"人民共和","frequency":20,"pos":"nz"},{"text":"华人","frequency":30}]`, auth...)
+	expect(t, "200", code)
+	expect(t, `{"num_tokens":9,"total_frequency":650,"max_token_length":7,"user_words":2,"removed_words":0}`, body)
+	code, body = serve(AdminServer, "GET", "/admin/words?text=%E5%8D%8E%E4%BA%BA", "", auth...)
+	expect(t, "200", code)
+	expect(t, `{"text":"华人","frequency":30,"pos":""}`, body)
+	code, body = serve(AdminServer, "DELETE", "/admin/words?text=%E5%9B%BD", "", auth...)
+	expect(t, "200", code)
+	expect(t, `{"num_tokens":8,"total_frequency":550,"max_token_length":7,"user_words":2,"removed_words":1}`, body)
+	code, _ = serve(AdminServer, "GET", "/admin/words?text=%E5%9B%BD", "", auth...)
+	expect(t, "404", code)
+	content, _ := os.ReadFile(*userDict)
+	expect(t, "人民共和 20 nz\n华人 30\n国 0\n", string(content))
+
+	// 修改用户词典文件后重新载入
+	os.WriteFile(*userDict, []byte("华人 40\n"), 0644)
+	code, body = serve(AdminServer, "POST", "/admin/reload", "", auth...)
+	expect(t, "200", code)
+	expect(t, `{"num_tokens":8,"total_frequency":640,"max_token_length":7,"user_words":1,"removed_words":0}`, body)
+	code, body = serve(AdminServer, "GET", "/admin/words?text=%E5%8D%8E%E4%BA%BA", "", auth...)
+	expect(t, "200", code)
+	expect(t, `{"text":"华人","frequency":40,"pos":""}`, body)
+
+	// 错误
+	code, _ = serve(AdminServer, "DELETE", "/admin/words?text=%E6%B2%A1%E6%9C%89", "", auth...)
+	expect(t, "404", code)
+	code, _ = serve(AdminServer, "POST", "/admin/words", `{"text":"新词","frequency":1}`, auth...)
+	expect(t, "400", code)
+	code, _ = serve(AdminServer, "POST", "/admin/words", `{"text":"新 词","frequency":10}`, auth...)
+	expect(t, "400", code)
+	code, body = serve(AdminServer, "POST", "/admin/words", `{"text":"新词","frequency":10,"tag":"x"}`, auth...)
+	expect(t, "400", code)
+	expect(t, `{"error":"request body is invalid: json: unknown field \"tag\""}`, body)
+	code, body = serve(AdminServer, "POST", "/admin/words", `[{"text":"新词","frequency":10}] []`, auth...)
+	expect(t, "400", code)
+	expect(t, `{"error":"request body is invalid: unexpected data after the JSON value"}`, body)
+	*maxBodyBytes = 10
+	code, body = serve(AdminServer, "POST", "/admin/words", `{"text":"新词","frequency":10}`, auth...)
+	*maxBodyBytes = 10 << 20
+	expect(t, "413", code)
+	expect(t, `{"error":"request body is too large"}`, body)
+	code, _ = serve(AdminServer, "PUT", "/admin/words", "", auth...)
+	expect(t, "405", code)
+	code, _ = serve(AdminServer, "GET", "/admin/other", "", auth...)
+	expect(t, "404", code)
+}
+
+func TestMetricsServer(t *testing.T) {
+	metrics = serverMetrics{
+		requests:  map[requestKey]uint64{},
+		latencies: map[string]*histogram{},
+		reloads:   map[bool]uint64{},
+	}
+	var logs bytes.Buffer
+	accessLog = json.NewEncoder(&logs)
+	defer func() { accessLog = nil }()
+
+	// 一次成功和一次失败的词典载入
+	reloadDictionary()
+	*dict = "../testdata/no_such_dict.txt"
+	reloadDictionary()
+	*dict = "../testdata/test_dict4.txt"
+
+	serve(instrument("json", JsonRpcServer), "GET", "/json?text=%E4%B8%AD%E5%8D%8E%E5%9B%BD", "")
+	serve(instrument("json", JsonRpcServer), "GET", "/json", "")
+	expect(t, "true", strings.Contains(logs.String(), `"handler":"json","method":"GET","path":"/json","status":400`))
+
+	code, body := serve(MetricsServer, "GET", "/metrics", "")
+	expect(t, "200", code)
+	for _, line := range []string{
+		`sego_http_requests_total{handler="json",code="200"} 1`,
+		`sego_http_requests_total{handler="json",code="400"} 1`,
+		`sego_http_request_duration_seconds_bucket{handler="json",le="+Inf"} 2`,
+		`sego_http_request_duration_seconds_count{handler="json"} 2`,
+		`sego_segmented_bytes_total 9`,
+		`sego_dictionary_tokens 7`,
+		`sego_dictionary_total_frequency 600`,
+		`sego_dictionary_reloads_total{result="success"} 1`,
+		`sego_dictionary_reloads_total{result="failure"} 1`,
+		`sego_dictionary_last_reload_success 0`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("/metrics的输出中没有%s", line)
+		}
+	}
+}
+
+func TestReadyServer(t *testing.T) {
+	code, body := serve(HealthServer, "GET", "/healthz", "")
+	expect(t, "200 ok", fmt.Sprint(code, " ", body))
+	code, body = serve(ReadyServer, "GET", "/readyz", "")
+	expect(t, "200 ok", fmt.Sprint(code, " ", body))
+
+	// 词典载入之前
+	seg := currentSegmenter()
+	setSegmenter(nil)
+	code, body = serve(ReadyServer, "GET", "/readyz", "")
+	expect(t, "503 dictionary is loading", fmt.Sprint(code, " ", body))
+	code, _ = serve(JsonRpcServer, "GET", "/json?text=a", "")
+	expect(t, "503", code)
+	setSegmenter(seg)
+}
@@ -1,5 +1,10 @@
 package sego
 
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
 // 字串类型，可以用来表达
 //	1. 一个字元，比如"中"又如"国", 英文的一个字元是一个词
 //	2. 一个分词，比如"中国"又如"人口"
@@ -41,6 +46,33 @@ func (token *Token) Pos() string {
 	return token.pos
 }
 
+// 返回分词在动态规划中的路径长度，见Token结构体中distance的注释
+func (token *Token) Distance() float32 {
+	return token.distance
+}
+
+// 分词是否包含文字（汉字、字母或数字），只由标点、空白等组成的分词不能作为
+// 索引或查询的词，见IndexTerms
+func (token *Token) IsTerm() bool {
+	for _, word := range token.text {
+		if isWordCharacter(word) {
+			return true
+		}
+	}
+	return false
+}
+
+// 判断字元是否为文字（汉字、字母或数字），标点和空白等字元不能出现在词中
+func isWordCharacter(word Text) bool {
+	r, _ := utf8.DecodeRune(word)
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+// 返回分词包含的字元数，一个汉字或一个英文单词为一个字元
+func (token *Token) Length() int {
+	return len(token.text)
+}
+
 // 该分词文本的进一步分词划分，比如"中华人民共和国中央人民政府"这个分词
 // 有两个子分词"中华人民共和国"和"中央人民政府"。子分词也可以进一步有子分词
 // 形成一个树结构，遍历这个树就可以得到该分词的所有细致分词划分，这主要
@@ -20,6 +20,10 @@ go build benchmark.go
 ./benchmark -memprofile=mem.prof
 go tool pprof benchmark mem.prof
 
+比较Segment、Each和SegmentInto每次调用的内存分配：
+
+go run benchmark.go -allocs
+
 */
 
 package main
@@ -33,6 +37,7 @@ import (
 	"os"
 	"runtime"
 	"runtime/pprof"
+	"testing"
 	"time"
 )
 
@@ -40,6 +45,7 @@ var (
 	cpuprofile = flag.String("cpuprofile", "", "处理器profile文件")
 	memprofile = flag.String("memprofile", "", "内存profile文件")
 	output     = flag.String("output", "", "输出分词结果到此文件")
+	allocs     = flag.Bool("allocs", false, "比较各分词函数每次调用的内存分配")
 	numRuns    = 20
 )
 
@@ -90,13 +96,15 @@ func main() {
 	}
 
 	// 当指定输出文件时打开输出文件
-	var of *os.File
+	var of *bufio.Writer
 	if *output != "" {
-		of, err = os.Create(*output)
+		file, err := os.Create(*output)
 		if err != nil {
 			log.Fatal(err)
 		}
-		defer of.Close()
+		defer file.Close()
+		of = bufio.NewWriter(file)
+		defer of.Flush()
 	}
 
 	// 记录时间
@@ -117,8 +125,8 @@ func main() {
 		for _, l := range lines {
 			segments := segmenter.Segment(l)
 			if *output != "" {
-				of.WriteString(sego.SegmentsToString(segments, false))
-				of.WriteString("\n")
+				sego.WriteSegments(of, segments, false, sego.WordPosFormat)
+				of.WriteByte('\n')
 			}
 		}
 	}
@@ -132,4 +140,48 @@ func main() {
 	t3 := time.Now()
 	log.Printf("分词花费时间 %v", t3.Sub(t2))
 	log.Printf("分词速度 %f MB/s", float64(size*numRuns)/t3.Sub(t2).Seconds()/(1024*1024))
+
+	// 比较内存分配
+	if *allocs {
+		benchmarkAllocs(&segmenter, lines)
+	}
+}
+
+// 对每个分词函数逐行分词，输出每行的分词时间和内存分配
+func benchmarkAllocs(segmenter *sego.Segmenter, lines [][]byte) {
+	benchmarks := []struct {
+		name string
+		fn   func(b *testing.B)
+	}{
+		{"Segment", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				segmenter.Segment(lines[i%len(lines)])
+			}
+		}},
+		{"Each", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				segmenter.Each(lines[i%len(lines)], func(sego.Segment) bool { return true })
+			}
+		}},
+		{"SegmentInto(nil)", func(b *testing.B) {
+			var dst []sego.Segment
+			for i := 0; i < b.N; i++ {
+				dst = segmenter.SegmentInto(dst[:0], lines[i%len(lines)], nil)
+			}
+		}},
+		{"SegmentInto(Workspace)", func(b *testing.B) {
+			var ws sego.Workspace
+			var dst []sego.Segment
+			for i := 0; i < b.N; i++ {
+				dst = segmenter.SegmentInto(dst[:0], lines[i%len(lines)], &ws)
+			}
+		}},
+	}
+	for _, benchmark := range benchmarks {
+		result := testing.Benchmark(func(b *testing.B) {
+			b.ReportAllocs()
+			benchmark.fn(b)
+		})
+		log.Printf("%-24s %s %s", benchmark.name, result.String(), result.MemString())
+	}
 }
@@ -0,0 +1,81 @@
+/*
+
+用SIGHAN bakeoff格式的标准分词语料评测sego分词准确率
+
+go run evaluate.go -gold=pku_test_gold.utf8
+
+指定训练语料作为登录词表，并输出分词结果不同的句子：
+
+go run evaluate.go -gold=pku_test_gold.utf8 -vocabulary=pku_training.utf8 -diff=diff.txt
+
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/huichen/sego"
+	"github.com/huichen/sego/evaluation"
+)
+
+var (
+	dict       = flag.String("dict", "../data/dictionary.txt", "词典文件，多个文件用\",\"分隔")
+	gold       = flag.String("gold", "", "标准分词语料文件")
+	vocabulary = flag.String("vocabulary", "", "登录词表文件，为空时以词典作为登录词表")
+	diff       = flag.String("diff", "", "输出分词结果和标准不同的句子到此文件")
+)
+
+func main() {
+	flag.Parse()
+
+	if *gold == "" {
+		log.Fatal("请用-gold指定标准分词语料文件")
+	}
+
+	var segmenter sego.Segmenter
+	segmenter.LoadDictionary(*dict)
+
+	options := evaluation.Options{}
+	if *vocabulary != "" {
+		file, err := os.Open(*vocabulary)
+		if err != nil {
+			log.Fatal(err)
+		}
+		options.Vocabulary, err = evaluation.ReadVocabulary(file)
+		file.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *diff != "" {
+		options.MaxDiffs = -1
+	}
+
+	// 评测
+	file, err := os.Open(*gold)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+	result, err := evaluation.Evaluate(&segmenter, file, options)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(result)
+
+	// 输出分词结果不同的句子
+	if *diff != "" {
+		of, err := os.Create(*diff)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer of.Close()
+		if err := result.WriteDiffs(of); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
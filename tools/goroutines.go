@@ -4,6 +4,7 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"github.com/huichen/sego"
 	"log"
@@ -15,18 +16,9 @@ import (
 var (
 	segmenter  = sego.Segmenter{}
 	numThreads = runtime.NumCPU()
-	task       = make(chan []byte, numThreads*40)
-	done       = make(chan bool, numThreads)
 	numRuns    = 50
 )
 
-func worker() {
-	for line := range task {
-		segmenter.Segment(line)
-	}
-	done <- true
-}
-
 func main() {
 	// 将线程数设置为CPU数
 	runtime.GOMAXPROCS(numThreads)
@@ -53,10 +45,6 @@ func main() {
 		lines = append(lines, content)
 	}
 
-	// 启动工作线程
-	for i := 0; i < numThreads; i++ {
-		go worker()
-	}
 	log.Print("开始分词")
 
 	// 记录时间
@@ -64,16 +52,10 @@ func main() {
 
 	// 并行分词
 	for i := 0; i < numRuns; i++ {
-		for _, l := range lines {
-			task <- l
+		if _, err := segmenter.SegmentBatch(context.Background(), lines, numThreads); err != nil {
+			log.Fatal(err)
 		}
 	}
-	close(task)
-
-	// 确保分词完成
-	for i := 0; i < numThreads; i++ {
-		<-done
-	}
 
 	// 记录时间并计算分词速度
 	t1 := time.Now()
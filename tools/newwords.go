@@ -0,0 +1,77 @@
+/*
+
+从语料中发现新词，输出为sego词典格式
+
+go run newwords.go -input=corpus.txt -output=newwords.txt
+
+输出的每行为"分词文本 频率 词性"，可以作为用户词典和通用词典一起载入：
+
+segmenter.LoadDictionary("newwords.txt,../data/dictionary.txt")
+
+*/
+
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"github.com/huichen/sego"
+)
+
+var (
+	dict       = flag.String("dict", "../data/dictionary.txt", "词典文件，已在词典中的词不会输出，多个文件用\",\"分隔")
+	input      = flag.String("input", "", "语料文件")
+	output     = flag.String("output", "", "输出新词到此文件，为空时输出到标准输出")
+	maxLength  = flag.Int("max_length", 4, "新词最多包含的字元数")
+	minFreq    = flag.Int("min_freq", 5, "新词在语料中的最小出现次数")
+	minPMI     = flag.Float64("min_pmi", 3, "新词内部凝固度的下限")
+	minEntropy = flag.Float64("min_entropy", 1, "新词左右邻字信息熵的下限，0表示不限制")
+	pos        = flag.String("pos", "", "输出新词的词性标注")
+)
+
+func main() {
+	flag.Parse()
+
+	if *input == "" {
+		log.Fatal("请用-input指定语料文件")
+	}
+
+	var segmenter sego.Segmenter
+	segmenter.LoadDictionary(*dict)
+
+	// 扫描语料
+	file, err := os.Open(*input)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	finder := sego.NewNewWordFinder(sego.NewWordOptions{
+		MaxWordLength: *maxLength,
+		MinFrequency:  *minFreq,
+		MinPMI:        *minPMI,
+		MinEntropy:    *minEntropy,
+	})
+	if err := finder.AddReader(file); err != nil {
+		log.Fatal(err)
+	}
+	words := finder.Find(segmenter.Dictionary())
+	log.Printf("发现新词 %d 个", len(words))
+
+	// 输出新词
+	var writer io.Writer = os.Stdout
+	if *output != "" {
+		of, err := os.Create(*output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer of.Close()
+		writer = of
+	}
+	if err := sego.WriteNewWords(writer, words, *pos); err != nil {
+		log.Fatal(err)
+	}
+}
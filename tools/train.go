@@ -0,0 +1,106 @@
+/*
+
+从已分词的语料训练词频，并合并到已有的词典文件中
+
+go run train.go -corpus=segmented.txt -dict=../data/dictionary.txt -output=dictionary.txt
+
+语料每行一句，分词之间用空白分隔，分词后可以带词性标注，比如
+
+	中国/ns 有/v 十三亿/m 人口/n
+
+训练过程见sego.FrequencyTrainer的注释。语料中没有出现的分词保持词典词频不变，
+因此可以用较小的领域语料调整通用词典。
+
+*/
+
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/huichen/sego"
+)
+
+var (
+	corpus = flag.String("corpus", "", "已分词的语料文件")
+	dict   = flag.String("dict", "../data/dictionary.txt", "需要合并的词典文件")
+	output = flag.String("output", "", "输出训练后的词典到此文件，为空时输出到标准输出")
+	alpha  = flag.Float64("alpha", 0.5, "语料词频的加alpha平滑参数")
+	weight = flag.Float64("weight", 0.5, "语料词频在合并时的权重，取值0到1")
+)
+
+func main() {
+	flag.Parse()
+
+	if *corpus == "" {
+		log.Fatal("请用-corpus指定已分词的语料文件")
+	}
+	if *weight < 0 || *weight > 1 {
+		log.Fatal("-weight的取值必须在0到1之间")
+	}
+
+	// 统计语料中的词频和词性
+	corpusFile, err := os.Open(*corpus)
+	if err != nil {
+		log.Fatal(err)
+	}
+	trainer := sego.NewFrequencyTrainer()
+	err = trainer.AddReader(corpusFile)
+	corpusFile.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("语料中共有 %d 个分词，%d 个不同的分词", trainer.NumWords(), trainer.NumDistinctWords())
+	logPosCounts(trainer.PosCounts())
+
+	// 载入词典
+	dictFile, err := os.Open(*dict)
+	if err != nil {
+		log.Fatal(err)
+	}
+	entries, err := sego.ReadDictionary(dictFile)
+	dictFile.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("词典中共有 %d 个分词", len(entries))
+
+	// 平滑并合并词频
+	merged := trainer.Merge(entries, sego.TrainOptions{Alpha: *alpha, Weight: *weight})
+	log.Printf("语料中新增 %d 个分词", len(merged)-len(entries))
+
+	// 输出词典
+	var writer io.Writer = os.Stdout
+	if *output != "" {
+		of, err := os.Create(*output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer of.Close()
+		writer = of
+	}
+	if err := sego.WriteDictionary(writer, merged); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// 输出语料中各词性的出现次数
+func logPosCounts(totals map[string]int) {
+	tags := make([]string, 0, len(totals))
+	for pos := range totals {
+		tags = append(tags, pos)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if totals[tags[i]] != totals[tags[j]] {
+			return totals[tags[i]] > totals[tags[j]]
+		}
+		return tags[i] < tags[j]
+	})
+	for _, pos := range tags {
+		log.Printf("词性 %s 出现 %d 次", pos, totals[pos])
+	}
+}
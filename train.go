@@ -0,0 +1,260 @@
+package sego
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 词典文件中的一个分词
+type DictionaryEntry struct {
+	Text string
+
+	// 词频，训练得到的词频不一定是整数，输出时四舍五入
+	Frequency float64
+
+	// 词性，没有标注时为空
+	Pos string
+}
+
+// 词频训练的参数
+type TrainOptions struct {
+	// 语料词频的加alpha平滑参数
+	Alpha float64
+
+	// 语料词频在合并时的权重，取值0到1
+	Weight float64
+}
+
+// 词频训练器：统计已分词语料中的词频和词性，并合并到已有的词典中
+//
+// 训练过程：
+//
+//  1. 统计语料中每个分词的出现次数c(w)以及词性的出现次数
+//  2. 对语料中出现过的分词做加alpha平滑：p语料(w) = (c(w)+alpha) / (C+alpha*V)，
+//     其中C为语料总词数，V为语料中不同分词的数目
+//  3. 对语料中出现过的分词和词典词频插值：p(w) = weight*p语料(w) + (1-weight)*p词典(w)，
+//     语料中没有出现的分词保持词典词频不变
+//  4. 输出的词频为p(w)乘以词典总词频，这样和未训练的词典处于同一量级
+//
+// 平滑只在语料的词表上进行，因此语料远小于词典时词典中的低频词不会被抬高。
+// 词性取语料中该分词出现最多的词性，语料中没有标注时沿用词典中的词性。
+//
+// 和LoadDictionary相同，英文不区分大小写：只有大小写不同的分词（比如iPhone和
+// iphone）视为同一个分词，词频相加，输出第一次出现时的写法。
+type FrequencyTrainer struct {
+	// 键为dictionaryKey得到的分词的规范形式
+	counts    map[string]int
+	posCounts map[string]map[string]int
+	total     int64
+
+	// 分词第一次出现时的写法
+	texts map[string]string
+}
+
+// 创建词频训练器
+func NewFrequencyTrainer() *FrequencyTrainer {
+	return &FrequencyTrainer{
+		counts:    make(map[string]int),
+		posCounts: make(map[string]map[string]int),
+		texts:     make(map[string]string),
+	}
+}
+
+// 统计一行已分词的语料，分词之间用空白分隔，分词后可以带"/词性"标注，比如
+//
+//	中国/ns 有/v 十三亿/m 人口/n
+func (trainer *FrequencyTrainer) Add(line string) {
+	for _, field := range strings.Fields(line) {
+		word, pos := splitWordPos(field)
+		key := dictionaryKey(word)
+		if _, ok := trainer.texts[key]; !ok {
+			trainer.texts[key] = word
+		}
+		trainer.counts[key]++
+		trainer.total++
+		if pos != "" {
+			if trainer.posCounts[key] == nil {
+				trainer.posCounts[key] = make(map[string]int)
+			}
+			trainer.posCounts[key][pos]++
+		}
+	}
+}
+
+// 逐行统计reader中的语料直到读完
+func (trainer *FrequencyTrainer) AddReader(reader io.Reader) error {
+	scanner := newLineScanner(reader)
+	for scanner.Scan() {
+		trainer.Add(scanner.Text())
+	}
+	return scanner.Err()
+}
+
+// 语料的总词数
+func (trainer *FrequencyTrainer) NumWords() int64 {
+	return trainer.total
+}
+
+// 语料中不同分词的数目
+func (trainer *FrequencyTrainer) NumDistinctWords() int {
+	return len(trainer.counts)
+}
+
+// 语料中各词性的出现次数
+func (trainer *FrequencyTrainer) PosCounts() map[string]int {
+	totals := make(map[string]int)
+	for _, counts := range trainer.posCounts {
+		for pos, count := range counts {
+			totals[pos] += count
+		}
+	}
+	return totals
+}
+
+// 将语料词频合并到词典中，返回合并后的词典，entries不被修改
+//
+// 输出依次为词典中的分词（顺序不变）和语料中新出现的分词，后者按语料词频从高
+// 到低排序。
+func (trainer *FrequencyTrainer) Merge(entries []DictionaryEntry, options TrainOptions) []DictionaryEntry {
+	output := make([]DictionaryEntry, len(entries), len(entries)+len(trainer.counts))
+	copy(output, entries)
+	dictTotal := 0.0
+	index := make(map[string]int)
+	for i, entry := range output {
+		dictTotal += entry.Frequency
+		index[dictionaryKey(entry.Text)] = i
+	}
+
+	// 语料中新出现的分词按词频从高到低追加到词典之后
+	newWords := []string{}
+	for key := range trainer.counts {
+		if _, ok := index[key]; !ok {
+			newWords = append(newWords, key)
+		}
+	}
+	sort.Slice(newWords, func(i, j int) bool {
+		if trainer.counts[newWords[i]] != trainer.counts[newWords[j]] {
+			return trainer.counts[newWords[i]] > trainer.counts[newWords[j]]
+		}
+		return newWords[i] < newWords[j]
+	})
+	for _, key := range newWords {
+		index[key] = len(output)
+		output = append(output, DictionaryEntry{Text: trainer.texts[key]})
+	}
+
+	// 词典为空时以语料总词数为词频量级
+	scale := dictTotal
+	if scale == 0 {
+		scale = float64(trainer.total)
+	}
+
+	// 只平滑并合并语料中出现过的分词
+	denominator := float64(trainer.total) + options.Alpha*float64(len(trainer.counts))
+	for key, count := range trainer.counts {
+		entry := &output[index[key]]
+		pCorpus := (float64(count) + options.Alpha) / denominator
+		pDict := 0.0
+		if dictTotal > 0 {
+			pDict = entry.Frequency / dictTotal
+		}
+		entry.Frequency = (options.Weight*pCorpus + (1-options.Weight)*pDict) * scale
+		if pos := topPos(trainer.posCounts[key]); pos != "" {
+			entry.Pos = pos
+		}
+	}
+	return output
+}
+
+// 将"分词/词性"拆分为分词和词性，只有斜杠后全是英文字母时才视为词性标注
+func splitWordPos(field string) (word, pos string) {
+	slash := strings.LastIndex(field, "/")
+	if slash <= 0 || slash == len(field)-1 {
+		return field, ""
+	}
+	for _, c := range field[slash+1:] {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			return field, ""
+		}
+	}
+	return field[:slash], field[slash+1:]
+}
+
+// 返回出现次数最多的词性，次数相同时取字典序较小的词性
+func topPos(counts map[string]int) (pos string) {
+	maxCount := 0
+	for p, count := range counts {
+		if count > maxCount || count == maxCount && p < pos {
+			pos = p
+			maxCount = count
+		}
+	}
+	return
+}
+
+// 按LoadDictionary相同的格式读入词典。和LoadDictionary相同，英文不区分大小写，
+// 同一分词多次出现时保留第一次出现的写法和词性（第一次没有词性时取之后的词性），
+// 词频相加。和LoadDictionary不同，低频分词也被读入，以便原样输出。
+func ReadDictionary(reader io.Reader) ([]DictionaryEntry, error) {
+	entries := []DictionaryEntry{}
+	index := make(map[string]int)
+	scanner := newLineScanner(reader)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			// 无效行
+			continue
+		}
+		frequency, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		pos := ""
+		if len(fields) > 2 {
+			pos = fields[2]
+		}
+		key := dictionaryKey(fields[0])
+		if i, ok := index[key]; ok {
+			entries[i].Frequency += float64(frequency)
+			if entries[i].Pos == "" {
+				entries[i].Pos = pos
+			}
+			continue
+		}
+		index[key] = len(entries)
+		entries = append(entries, DictionaryEntry{Text: fields[0], Frequency: float64(frequency), Pos: pos})
+	}
+	return entries, scanner.Err()
+}
+
+// 分词在词典中的规范形式，和LoadDictionary相同，英文字母转为小写
+func dictionaryKey(text string) string {
+	return string(textSliceToBytes(splitTextToWords([]byte(text))))
+}
+
+// 以词典文件的格式输出词典，词频四舍五入为整数。词频低于LoadDictionary载入的
+// 最低词频的分词仍然输出，载入时会被忽略。
+func WriteDictionary(writer io.Writer, entries []DictionaryEntry) error {
+	bufWriter := bufio.NewWriter(writer)
+	for _, entry := range entries {
+		frequency := int64(math.Round(entry.Frequency))
+		if entry.Pos == "" {
+			fmt.Fprintf(bufWriter, "%s %d\n", entry.Text, frequency)
+		} else {
+			fmt.Fprintf(bufWriter, "%s %d %s\n", entry.Text, frequency, entry.Pos)
+		}
+	}
+	return bufWriter.Flush()
+}
+
+// 返回允许较长行的bufio.Scanner
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	return scanner
+}
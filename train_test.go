@@ -0,0 +1,52 @@
+package sego
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFrequencyTrainer(t *testing.T) {
+	entries, err := ReadDictionary(strings.NewReader("中国 100 ns\n人口 50 n\n稀有 1 a\n中国 7\n无效行\n"))
+	expect(t, "<nil>", err)
+	expect(t, "3", len(entries))
+
+	trainer := NewFrequencyTrainer()
+	expect(t, "<nil>", trainer.AddReader(strings.NewReader("中国/ns 人口/n\n新词/nz 中国/nr\n\n中国/ns")))
+	expect(t, "5", trainer.NumWords())
+	expect(t, "3", trainer.NumDistinctWords())
+	expect(t, "map[n:1 nr:1 ns:2 nz:1]", trainer.PosCounts())
+
+	merged := trainer.Merge(entries, TrainOptions{Alpha: 0.5, Weight: 0.5})
+	var buf bytes.Buffer
+	expect(t, "<nil>", WriteDictionary(&buf, merged))
+	// 语料中没有出现的"稀有"保持原词频，不被平滑抬高
+	expect(t, "中国 96 ns\n人口 43 n\n稀有 1 a\n新词 18 nz\n", buf.String())
+
+	// 原词典不被修改
+	expect(t, "107", entries[0].Frequency)
+}
+
+func TestFrequencyTrainerIgnoresCase(t *testing.T) {
+	// 和LoadDictionary相同，只有大小写不同的分词是同一个分词
+	entries, err := ReadDictionary(strings.NewReader("iPhone 30 nz\niphone 10\n手机 60 n\n"))
+	expect(t, "<nil>", err)
+	expect(t, "[{iPhone 40 nz} {手机 60 n}]", entries)
+
+	trainer := NewFrequencyTrainer()
+	trainer.Add("IPHONE/nz 手机/n iphone 新款 新款")
+	expect(t, "3", trainer.NumDistinctWords())
+
+	var buf bytes.Buffer
+	expect(t, "<nil>", WriteDictionary(&buf, trainer.Merge(entries, TrainOptions{Weight: 1})))
+	expect(t, "iPhone 40 nz\n手机 20 n\n新款 40\n", buf.String())
+}
+
+func TestSplitWordPos(t *testing.T) {
+	word, pos := splitWordPos("中国/ns")
+	expect(t, "中国 ns", word+" "+pos)
+	word, pos = splitWordPos("1/2")
+	expect(t, "1/2 ", word+" "+pos)
+	word, pos = splitWordPos("/")
+	expect(t, "/ ", word+" "+pos)
+}
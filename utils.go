@@ -1,34 +1,71 @@
 package sego
 
 import (
+	"bufio"
 	"bytes"
-	"fmt"
+	"io"
+	"strings"
 )
 
-// 输出分词结果为字符串
-//
-// 有两种输出模式，以"中华人民共和国"为例
+// 分词结果的文本输出格式
+type OutputFormat int
+
+const (
+	// "分词/词性 "，即SegmentsToString的格式
+	WordPosFormat OutputFormat = iota
+
+	// "分词 "
+	WordFormat
+)
+
+// 将分词结果直接输出到w，不产生中间字符串，输出的内容和SegmentsToString相同
 //
-//  普通模式（searchMode=false）输出一个分词"中华人民共和国/ns "
-//  搜索模式（searchMode=true） 输出普通模式的再细致切分：
-//      "中华/nz 人民/n 共和/nz 共和国/ns 人民共和国/nt 中华人民共和国/ns "
+// 搜索模式（searchMode=true）下输出分词的再细致切分，见SegmentsToString。
 //
-// 搜索模式主要用于给搜索引擎提供尽可能多的关键字，详情请见Token结构体的注释。
-func SegmentsToString(segs []Segment, searchMode bool) (output string) {
-	if searchMode {
-		for _, seg := range segs {
-			output += tokenToString(seg.token)
+// w为*bufio.Writer时直接写入其中且不调用Flush，以便逐行输出时保持缓冲，写入的
+// 错误由调用者的Flush返回；否则使用临时的bufio.Writer并在返回前Flush。
+func WriteSegments(w io.Writer, segs []Segment, searchMode bool, format OutputFormat) error {
+	if writer, ok := w.(*bufio.Writer); ok {
+		writeSegments(writer, segs, searchMode, format)
+		return nil
+	}
+	writer := bufio.NewWriter(w)
+	writeSegments(writer, segs, searchMode, format)
+	return writer.Flush()
+}
+
+// bufio.Writer和strings.Builder都实现了的输出接口
+type textWriter interface {
+	io.Writer
+	io.ByteWriter
+	io.StringWriter
+}
+
+func writeSegments(w textWriter, segs []Segment, searchMode bool, format OutputFormat) {
+	visitSegments(segs, searchMode, func(token *Token) {
+		for _, word := range token.text {
+			w.Write(word)
+		}
+		if format == WordPosFormat {
+			w.WriteByte('/')
+			w.WriteString(token.pos)
 		}
-	} else {
-		for _, seg := range segs {
-			output += fmt.Sprintf(
-				"%s/%s ", textSliceToString(seg.token.text), seg.token.pos)
+		w.WriteByte(' ')
+	})
+}
+
+// 按输出的顺序对每个分词调用visit，搜索模式下先访问分词的子分词再访问分词本身
+func visitSegments(segs []Segment, searchMode bool, visit func(token *Token)) {
+	for _, seg := range segs {
+		if searchMode {
+			visitToken(seg.token, visit)
+		} else {
+			visit(seg.token)
 		}
 	}
-	return
 }
 
-func tokenToString(token *Token) (output string) {
+func visitToken(token *Token, visit func(token *Token)) {
 	hasOnlyTerminalToken := true
 	for _, s := range token.segments {
 		if len(s.token.segments) > 1 {
@@ -39,12 +76,27 @@ func tokenToString(token *Token) (output string) {
 	if !hasOnlyTerminalToken {
 		for _, s := range token.segments {
 			if s != nil {
-				output += tokenToString(s.token)
+				visitToken(s.token, visit)
 			}
 		}
 	}
-	output += fmt.Sprintf("%s/%s ", textSliceToString(token.text), token.pos)
-	return
+	visit(token)
+}
+
+// 输出分词结果为字符串
+//
+// 有两种输出模式，以"中华人民共和国"为例
+//
+//  普通模式（searchMode=false）输出一个分词"中华人民共和国/ns "
+//  搜索模式（searchMode=true） 输出普通模式的再细致切分：
+//      "中华/nz 人民/n 共和/nz 共和国/ns 人民共和国/nt 中华人民共和国/ns "
+//
+// 搜索模式主要用于给搜索引擎提供尽可能多的关键字，详情请见Token结构体的注释。
+// 需要调整搜索模式输出的粒度时，先用SearchSegments展开子分词再以普通模式输出。
+func SegmentsToString(segs []Segment, searchMode bool) string {
+	var output strings.Builder
+	writeSegments(&output, segs, searchMode, WordPosFormat)
+	return output.String()
 }
 
 // 输出分词结果到一个字符串slice
@@ -58,34 +110,12 @@ func tokenToString(token *Token) (output string) {
 // 搜索模式主要用于给搜索引擎提供尽可能多的关键字，详情请见Token结构体的注释。
 
 func SegmentsToSlice(segs []Segment, searchMode bool) (output []string) {
-	if searchMode {
-		for _, seg := range segs {
-			output = append(output, tokenToSlice(seg.token)...)
-		}
-	} else {
-		for _, seg := range segs {
-			output = append(output, seg.token.Text())
-		}
-	}
+	visitSegments(segs, searchMode, func(token *Token) {
+		output = append(output, token.Text())
+	})
 	return
 }
 
-func tokenToSlice(token *Token) (output []string) {
-	hasOnlyTerminalToken := true
-	for _, s := range token.segments {
-		if len(s.token.segments) > 1 {
-			hasOnlyTerminalToken = false
-		}
-	}
-	if !hasOnlyTerminalToken {
-		for _, s := range token.segments {
-			output = append(output, tokenToSlice(s.token)...)
-		}
-	}
-	output = append(output, textSliceToString(token.text))
-	return output
-}
-
 // 将多个字元拼接一个字符串输出
 func textSliceToString(text []Text) string {
 	return Join(text)
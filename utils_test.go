@@ -1,7 +1,10 @@
 package sego
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/issue9/assert"
@@ -142,3 +145,30 @@ func Test_Token_Split(t *testing.T) {
 		}
 	}
 }
+
+func TestWriteSegments(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	segments := seg.Segment([]byte("中国有十三亿人口"))
+
+	for _, searchMode := range []bool{false, true} {
+		var buf bytes.Buffer
+		expect(t, "<nil>", WriteSegments(&buf, segments, searchMode, WordPosFormat))
+		expect(t, SegmentsToString(segments, searchMode), buf.String())
+
+		buf.Reset()
+		expect(t, "<nil>", WriteSegments(&buf, segments, searchMode, WordFormat))
+		expect(t, strings.Join(SegmentsToSlice(segments, searchMode), " ")+" ", buf.String())
+	}
+	expect(t, "中国/ 有/p3 十三亿/ 人口/p12 ", SegmentsToString(segments, false))
+	expect(t, "中国/ 有/p3 十三/p10 亿/p5 十三亿/ 人口/p12 ",
+		SegmentsToString(segments, true))
+
+	// 调用者的bufio.Writer不被Flush
+	var buf bytes.Buffer
+	writer := bufio.NewWriter(&buf)
+	expect(t, "<nil>", WriteSegments(writer, segments, false, WordFormat))
+	expect(t, "0 28", fmt.Sprint(buf.Len(), writer.Buffered()))
+	writer.Flush()
+	expect(t, "中国 有 十三亿 人口 ", buf.String())
+}
@@ -0,0 +1,84 @@
+package sego
+
+import (
+	"context"
+	"sync"
+)
+
+// 分词时可以重复使用的内存，包括划分的字元、英文词的小写拷贝、分词网格、伪分词
+// 和分词结果。
+//
+// 零值即可使用。一个Workspace不能被多个goroutine同时使用。
+type Workspace struct {
+	words    []Text
+	lower    []byte
+	lattice  lattice
+	segments []Segment
+}
+
+var workspacePool = sync.Pool{
+	New: func() interface{} {
+		return new(Workspace)
+	},
+}
+
+// 对文本分词，将分词结果添加到dst之后并返回，分词结果和Segment相同
+//
+// ws不为nil时分词过程中的所有中间结果都使用ws中的内存，dst容量足够时不产生
+// 任何内存分配。此时词典中没有的字元和英文词对应的伪分词也存放在ws中，只在
+// 下一次使用ws之前有效。
+//
+// ws为nil时从缓冲池中取用Workspace，分词网格等中间结果不分配内存，伪分词单独
+// 分配内存，分词结果一直有效。
+func (seg *Segmenter) SegmentInto(dst []Segment, bytes []byte, ws *Workspace) []Segment {
+	// 处理特殊情况
+	if len(bytes) == 0 {
+		return dst
+	}
+
+	if ws != nil {
+		ws.segment(nil, seg, bytes, true)
+		return ws.lattice.appendBestPath(dst)
+	}
+
+	ws = workspacePool.Get().(*Workspace)
+	ws.segment(nil, seg, bytes, false)
+	dst = ws.lattice.appendBestPath(dst)
+	ws.release()
+	workspacePool.Put(ws)
+	return dst
+}
+
+// 划分字元并建立分词网格，reuseTokens为真时伪分词和英文词的小写拷贝也使用ws中
+// 的内存。ctx的用法见buildLattice。
+func (ws *Workspace) segment(ctx context.Context, seg *Segmenter, bytes []byte, reuseTokens bool) error {
+	if reuseTokens {
+		if cap(ws.lower) < len(bytes) {
+			ws.lower = make([]byte, 0, len(bytes))
+		}
+		ws.lower = ws.lower[:0]
+		ws.words = appendTextToWords(ws.words[:0], bytes, &ws.lower)
+	} else {
+		ws.words = appendTextToWords(ws.words[:0], bytes, nil)
+	}
+	ws.lattice.reuseTokens = reuseTokens
+	return seg.buildLattice(ctx, &ws.lattice, ws.words, false, nil)
+}
+
+// 清除对文本和分词的引用，以免放回缓冲池后阻止垃圾回收
+func (ws *Workspace) release() {
+	for i := range ws.words {
+		ws.words[i] = nil
+	}
+	for i := range ws.lattice.jumpers {
+		ws.lattice.jumpers[i].token = nil
+	}
+	for i := range ws.lattice.unknownTokens {
+		ws.lattice.unknownTokens[i].text = nil
+	}
+	for i := range ws.segments {
+		ws.segments[i].token = nil
+	}
+	ws.lattice.text = nil
+	ws.lattice.scorer = nil
+}
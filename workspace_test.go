@@ -0,0 +1,41 @@
+package sego
+
+import (
+	"testing"
+)
+
+func TestSegmentInto(t *testing.T) {
+	var seg Segmenter
+	seg.LoadDictionary("testdata/test_dict1.txt,testdata/test_dict2.txt")
+	text := []byte("中国有十三亿人口，GitHub和GITHUB")
+	expected := SegmentsToString(seg.Segment(text), false)
+
+	var ws Workspace
+	segments := seg.SegmentInto(nil, text, &ws)
+	expect(t, expected, SegmentsToString(segments, false))
+	expect(t, expected, SegmentsToString(seg.SegmentInto(nil, text, nil), false))
+
+	// 分词结果添加在dst之后
+	segments = seg.SegmentInto(segments[:1], []byte("人口"), &ws)
+	expect(t, "中国/ 人口/p12 ", SegmentsToString(segments, false))
+	expect(t, "0", segments[1].start)
+	expect(t, "6", segments[1].end)
+
+	// 使用Workspace且dst容量足够时没有内存分配
+	dst := make([]Segment, 0, 100)
+	allocs := testing.AllocsPerRun(100, func() {
+		dst = seg.SegmentInto(dst[:0], text, &ws)
+	})
+	expect(t, "0", allocs)
+	expect(t, expected, SegmentsToString(dst, false))
+
+	// Each使用sync.Pool中的Workspace。竞态检测下sync.Pool会随机丢弃对象，因此
+	// 不检查内存分配；GC也可能清空sync.Pool，因此只检查平均分配次数很少。
+	if raceEnabled {
+		return
+	}
+	allocs = testing.AllocsPerRun(100, func() {
+		seg.Each(text, func(Segment) bool { return true })
+	})
+	expect(t, "true", allocs <= 1)
+}